@@ -0,0 +1,161 @@
+package gocontainers
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Checkpointer periodically saves snapshots of CircularBuffer into a file
+// and loads them back at startup. Save may be called concurrently
+// with background checkpoints.
+type Checkpointer struct {
+	cb   *CircularBuffer
+	mu   sync.Locker
+	path string
+
+	saving sync.Mutex // serializes Save, since every Save writes the same temporary file
+	state  sync.Mutex // guards the fields below
+	saved  uint64     // overwrites counter at the last checkpoint
+	err    error      // the first error of background checkpoints
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewCheckpointer is the constructor function for Checkpointer.
+// If mu is not nil, it is held while cb is accessed.
+func NewCheckpointer(cb *CircularBuffer, mu sync.Locker, path string) *Checkpointer {
+	return &Checkpointer{cb: cb, mu: mu, path: path}
+}
+
+func (c *Checkpointer) lock() {
+	if c.mu != nil {
+		c.mu.Lock()
+	}
+}
+
+func (c *Checkpointer) unlock() {
+	if c.mu != nil {
+		c.mu.Unlock()
+	}
+}
+
+// Load restores CircularBuffer from the checkpoint file.
+// A missing file is not an error and leaves CircularBuffer intact.
+func (c *Checkpointer) Load() error {
+	data, e := os.ReadFile(c.path)
+	if os.IsNotExist(e) {
+		return nil
+	}
+	if e != nil {
+		return e
+	}
+	c.lock()
+	defer c.unlock()
+	return c.cb.Restore(data)
+}
+
+// Save writes the snapshot of CircularBuffer into a temporary file
+// and atomically renames it to the checkpoint file.
+func (c *Checkpointer) Save() error {
+	c.saving.Lock()
+	defer c.saving.Unlock()
+
+	c.lock()
+	data, e := c.cb.Snapshot()
+	overwrites := c.cb.Stats().Overwrites
+	c.unlock()
+	if e != nil {
+		return e
+	}
+
+	tmp := c.path + ".tmp"
+	f, e := os.Create(tmp)
+	if e != nil {
+		return e
+	}
+	_, e = f.Write(data)
+	if e == nil {
+		e = f.Sync()
+	}
+	if ce := f.Close(); e == nil {
+		e = ce
+	}
+	if e == nil {
+		e = os.Rename(tmp, c.path)
+	}
+	if e != nil {
+		os.Remove(tmp)
+		return e
+	}
+	c.state.Lock()
+	c.saved = overwrites
+	c.state.Unlock()
+	return nil
+}
+
+// Start saves checkpoints in background every interval until Stop.
+// If overwrites is positive, a checkpoint is saved only when at least
+// that many elements were overwritten since the previous one,
+// so interval becomes the polling period.
+// Start panics if background checkpoints are already running.
+func (c *Checkpointer) Start(interval time.Duration, overwrites uint64) {
+	c.state.Lock()
+	defer c.state.Unlock()
+	if c.stop != nil {
+		panic("gocontainers: Checkpointer.Start: already started")
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	c.stop, c.done = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if overwrites > 0 {
+					c.lock()
+					total := c.cb.Stats().Overwrites
+					c.unlock()
+					c.state.Lock()
+					pending := total - c.saved
+					c.state.Unlock()
+					if pending < overwrites {
+						continue
+					}
+				}
+				if e := c.Save(); e != nil {
+					c.state.Lock()
+					if c.err == nil {
+						c.err = e
+					}
+					c.state.Unlock()
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops background checkpoints and saves the final one.
+// It returns the first error of background checkpoints, if any.
+func (c *Checkpointer) Stop() error {
+	c.state.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.state.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	e := c.Save()
+	c.state.Lock()
+	if c.err != nil {
+		e, c.err = c.err, nil
+	}
+	c.state.Unlock()
+	return e
+}
@@ -0,0 +1,104 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCheckpointerLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0)
+
+	c := NewCheckpointer(&cb, nil, path)
+	assert.Nil(t, c.Load())
+	assert.Equal(t, cb.ToArray(), []interface{}{0})
+
+	c.Save()
+	rcb := NewCircularBuffer(1)
+	assert.Nil(t, NewCheckpointer(&rcb, nil, path).Load())
+	assert.Equal(t, rcb.ToArray(), []interface{}{0})
+
+	os.WriteFile(path, []byte{0}, 0644)
+	assert.NotNil(t, c.Load())
+}
+
+func TestCheckpointerSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoint")
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0)
+
+	c := NewCheckpointer(&cb, &sync.Mutex{}, path)
+	assert.Nil(t, c.Save())
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+
+	c = NewCheckpointer(&cb, nil, filepath.Join(dir, "missing", "checkpoint"))
+	assert.NotNil(t, c.Save())
+
+	c = NewCheckpointer(&cb, nil, path)
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.Save()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, errs, make([]error, 8))
+}
+
+func TestCheckpointerStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	var mu sync.Mutex
+	cb := NewCircularBuffer(2)
+
+	c := NewCheckpointer(&cb, &mu, path)
+	c.Start(time.Millisecond, 2)
+	assert.Panics(t, func() {
+		c.Start(time.Millisecond, 2)
+	})
+
+	mu.Lock()
+	cb.PushBack(0)
+	cb.PushBack(1)
+	cb.PushBack(2)
+	mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	_, e := os.Stat(path)
+	assert.True(t, os.IsNotExist(e))
+
+	mu.Lock()
+	cb.PushBack(3)
+	mu.Unlock()
+	for i := 0; i < 100; i++ {
+		if _, e = os.Stat(path); e == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, e)
+	assert.Nil(t, c.Stop())
+}
+
+func TestCheckpointerStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	cb := NewCircularBuffer(2)
+	cb.PushBack(0)
+
+	c := NewCheckpointer(&cb, nil, path)
+	c.Start(time.Hour, 0)
+	assert.Nil(t, c.Stop())
+
+	rcb := NewCircularBuffer(2)
+	NewCheckpointer(&rcb, nil, path).Load()
+	assert.Equal(t, rcb.ToArray(), []interface{}{0})
+}
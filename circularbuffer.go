@@ -1,41 +1,302 @@
 package gocontainers
 
-import "errors"
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxFormatElements limits the number of elements printed by String, GoString and %+v.
+const maxFormatElements = 32
+
+// Errors returned by CircularBuffer. They can be checked with errors.Is.
+var (
+	ErrEmpty           = errors.New("empty buffer")
+	ErrFull            = errors.New("full buffer")
+	ErrIndexOutOfRange = errors.New("index out of bounds")
+)
 
 // CircularBuffer is the basic class in gocontainers.
 // There are no public members in this struct.
 type CircularBuffer struct {
 	buffer   []interface{}
 	capacity int
+	ring     int // number of slots elements wrap around: capacity, rounded up to whole blocks if segmented
+	mask     int // ring-1 if ring is a power of two, -1 otherwise
 	shift    int
 	size     int
+	seq      uint64 // sequence number of the front element
+	stats    Stats
+
+	occupancy []uint64
+
+	codec   Codec
+	policy  OverwritePolicy
+	onEvict func(interface{})
+	lazy    bool // the backing array is allocated by the first push
+	pow2    bool // capacity is rounded up to a power of two by reset
+
+	blocks    [][]interface{} // segmented storage replacing buffer, see WithSegmentedStorage
+	blockSize int
+
+	growLimit int // maximum capacity reached by growth, 0 disables growth, -1 means no limit
+	shrink    shrinkPolicy
+}
+
+// shrinkPolicy is configured by WithAutoShrink.
+type shrinkPolicy struct {
+	threshold float64 // fraction of capacity considered low occupancy
+	after     int     // number of removals with low occupancy in a row, 0 disables shrinking
+	floor     int     // minimum capacity
+	low       int     // current number of removals with low occupancy in a row
 }
 
+// Stats contains cumulative counters of CircularBuffer.
+type Stats struct {
+	PushBacks  uint64 // number of PushBack calls
+	PushFronts uint64 // number of PushFront calls
+	PopBacks   uint64 // number of elements removed by PopBack
+	PopFronts  uint64 // number of elements removed by PopFront
+	Overwrites uint64 // number of elements dropped by pushes into full buffer
+	Size       int    // current number of elements
+	MaxSize    int    // maximum number of elements ever stored
+}
+
+// End selects an end of CircularBuffer.
+type End int
+
+const (
+	// AtFront selects the front end.
+	AtFront End = iota
+	// AtBack selects the back end.
+	AtBack
+)
+
 // NewCircularBuffer is the constructor function for CircularBuffer.
-func NewCircularBuffer(capacity int) CircularBuffer {
+// Options are applied in order.
+func NewCircularBuffer(capacity int, opts ...Option) CircularBuffer {
 	var cb CircularBuffer
 
-	cb.buffer = make([]interface{}, capacity)
-	cb.capacity = capacity
+	cb.setCapacity(capacity)
 	cb.shift = 0
 	cb.size = 0
 
+	for _, opt := range opts {
+		opt(&cb)
+	}
+	if !cb.lazy {
+		cb.allocate()
+	}
+
 	return cb
 }
 
+// NewCircularBufferFromSeq is the constructor function for CircularBuffer
+// filled with elements of seq. Only the last capacity elements are kept.
+func NewCircularBufferFromSeq(seq iter.Seq[interface{}], capacity int) CircularBuffer {
+	cb := NewCircularBuffer(capacity)
+	cb.AppendSeq(seq)
+	return cb
+}
+
+// NewCircularBufferFromSlice is the constructor function for full
+// CircularBuffer with elements of s. Capacity equals len(s). CircularBuffer
+// takes ownership of s, so it must not be modified by the caller afterwards.
+func NewCircularBufferFromSlice(s []interface{}) CircularBuffer {
+	var cb CircularBuffer
+
+	cb.buffer = s
+	cb.setCapacity(len(s))
+	cb.shift = 0
+	cb.size = len(s)
+	cb.updateMaxSize()
+
+	return cb
+}
+
+// NewCircularBufferWithValues is the constructor function for CircularBuffer
+// filled with vs. If more values than capacity are given, only the last
+// capacity values are kept, like with PushBack.
+func NewCircularBufferWithValues(capacity int, vs ...interface{}) CircularBuffer {
+	cb := NewCircularBuffer(capacity)
+	for _, v := range vs {
+		cb.PushBack(v)
+	}
+	return cb
+}
+
+// CompareFunc compares elements of CircularBuffers a and b lexicographically
+// using cmp like slices.CompareFunc. The result is the first non-zero result of cmp;
+// if a is a prefix of b or vice versa, the shorter one is less.
+func CompareFunc(a, b *CircularBuffer, cmp func(a, b interface{}) int) int {
+	for i := 0; i < min(a.size, b.size); i++ {
+		if c := cmp(*a.cell(a.slot(i)), *b.cell(b.slot(i))); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case a.size < b.size:
+		return -1
+	case a.size > b.size:
+		return 1
+	}
+	return 0
+}
+
+// Interleave takes elements of CircularBuffers bs round-robin, one from each
+// in turn, into a new CircularBuffer with the sum of their capacities.
+// Exhausted CircularBuffers are skipped until all of them are exhausted.
+func Interleave(bs ...*CircularBuffer) CircularBuffer {
+	capacity, size := 0, 0
+	for _, b := range bs {
+		capacity += b.capacity
+		size = max(size, b.size)
+	}
+	m := NewCircularBuffer(capacity)
+	for i := 0; i < size; i++ {
+		for _, b := range bs {
+			if i < b.size {
+				*m.cell(m.size) = *b.cell(b.slot(i))
+				m.size++
+			}
+		}
+	}
+	m.updateMaxSize()
+	return m
+}
+
+// MergeSorted merges CircularBuffers a and b sorted by cmp into a new sorted
+// CircularBuffer with the sum of their capacities. Elements of a go first among equal ones.
+func MergeSorted(a, b *CircularBuffer, cmp func(a, b interface{}) int) CircularBuffer {
+	m := NewCircularBuffer(a.capacity + b.capacity)
+	i, j := 0, 0
+	for i < a.size || j < b.size {
+		if j == b.size || i < a.size && cmp(*a.cell(a.slot(i)), *b.cell(b.slot(j))) <= 0 {
+			*m.cell(m.size) = *a.cell(a.slot(i))
+			i++
+		} else {
+			*m.cell(m.size) = *b.cell(b.slot(j))
+			j++
+		}
+		m.size++
+	}
+	m.updateMaxSize()
+	return m
+}
+
+// addBlocks appends n empty blocks to segmented storage.
+func (cb *CircularBuffer) addBlocks(n int) {
+	for i := 0; i < n; i++ {
+		cb.blocks = append(cb.blocks, make([]interface{}, cb.blockSize))
+	}
+}
+
+// All returns an iterator over indexes and elements of CircularBuffer
+// from the front to the back.
+func (cb *CircularBuffer) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		for i := 0; i < cb.size; i++ {
+			if !yield(i, *cb.cell(cb.slot(i))) {
+				return
+			}
+		}
+	}
+}
+
+// allocate creates the backing array unless it already exists.
+func (cb *CircularBuffer) allocate() {
+	switch {
+	case cb.blockSize > 0 && cb.blocks == nil:
+		cb.blocks = make([][]interface{}, 0, cb.ring/cb.blockSize)
+		cb.addBlocks(cb.ring / cb.blockSize)
+	case cb.blockSize == 0 && cb.buffer == nil:
+		cb.buffer = make([]interface{}, cb.capacity)
+	}
+}
+
+// allocated checks if the backing array or blocks of segmented storage exist.
+func (cb *CircularBuffer) allocated() bool {
+	return cb.buffer != nil || cb.blocks != nil
+}
+
+// AllPtr returns an iterator over indexes of elements of CircularBuffer
+// and pointers to slots holding them, so a range loop can change elements in place.
+// CircularBuffer must not be modified otherwise during iteration.
+func (cb *CircularBuffer) AllPtr() iter.Seq2[int, *interface{}] {
+	return func(yield func(int, *interface{}) bool) {
+		for i := 0; i < cb.size; i++ {
+			if !yield(i, cb.cell(cb.slot(i))) {
+				return
+			}
+		}
+	}
+}
+
+// AppendBuffer appends elements of other into CircularBuffer like PushBackSlice,
+// copying contiguous parts of other in bulk.
+// It returns the number of overwritten (or discarded) elements.
+func (cb *CircularBuffer) AppendBuffer(other *CircularBuffer) (overwritten int) {
+	if other == cb {
+		return cb.PushBackSlice(cb.ToArray())
+	}
+	for chunk := range other.Chunks() {
+		overwritten += cb.PushBackSlice(chunk)
+	}
+	return overwritten
+}
+
+// AppendSeq appends elements of seq into CircularBuffer with PushBack.
+func (cb *CircularBuffer) AppendSeq(seq iter.Seq[interface{}]) {
+	for v := range seq {
+		cb.PushBack(v)
+	}
+}
+
+// AppendTo appends elements of CircularBuffer to dst from the front to the back
+// and returns the extended slice. It allocates only if dst has no room left.
+func (cb *CircularBuffer) AppendTo(dst []interface{}) []interface{} {
+	for run := range cb.runs(0, cb.size) {
+		dst = append(dst, run...)
+	}
+	return dst
+}
+
 // At returns element from CircularBuffer by index.
+// Negative index counts from the back, so At(-1) returns the back element.
 func (cb *CircularBuffer) At(index int) (interface{}, error) {
-	if 0 <= index && index < cb.size {
-		return cb.buffer[(cb.shift+index)%cb.capacity], nil
+	if index, ok := cb.resolve(index); ok {
+		return *cb.cell(cb.slot(index)), nil
+	}
+	return nil, ErrIndexOutOfRange
+}
+
+// AtPtr returns a pointer to the slot holding element of CircularBuffer by index,
+// so the element can be replaced in place. The pointer is valid until the next
+// modification of CircularBuffer other than Set: pops, pushes into full buffer,
+// Resize, Clear and Restore reuse or replace the slot.
+// Negative index counts from the back.
+func (cb *CircularBuffer) AtPtr(index int) (*interface{}, error) {
+	if index, ok := cb.resolve(index); ok {
+		return cb.cell(cb.slot(index)), nil
 	}
-	return nil, errors.New("index out of bounds")
+	return nil, ErrIndexOutOfRange
 }
 
 // Back returns the back element in CircularBuffer.
 // In case of empty CircularBuffer nil returns.
 func (cb *CircularBuffer) Back() (interface{}, error) {
 	if cb.Empty() {
-		return nil, errors.New("empty buffer")
+		return nil, ErrEmpty
 	}
 	v, e := cb.At(cb.Size() - 1)
 	if e != nil {
@@ -44,17 +305,244 @@ func (cb *CircularBuffer) Back() (interface{}, error) {
 	return v, nil
 }
 
+// BackPtr returns a pointer to the slot holding the back element, see AtPtr.
+func (cb *CircularBuffer) BackPtr() (*interface{}, error) {
+	if cb.Empty() {
+		return nil, ErrEmpty
+	}
+	return cb.AtPtr(cb.size - 1)
+}
+
+// Backward returns an iterator over indexes and elements of CircularBuffer
+// from the back to the front.
+func (cb *CircularBuffer) Backward() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		for i := cb.size - 1; i >= 0; i-- {
+			if !yield(i, *cb.cell(cb.slot(i))) {
+				return
+			}
+		}
+	}
+}
+
 // Capacity returns the maximum possible number elements in CircularBuffer.
 func (cb *CircularBuffer) Capacity() int {
 	return cb.capacity
 }
 
+// cell returns a pointer to the slot with the given physical index.
+func (cb *CircularBuffer) cell(slot int) *interface{} {
+	if cb.blockSize > 0 {
+		return &cb.blocks[slot/cb.blockSize][slot%cb.blockSize]
+	}
+	return &cb.buffer[slot]
+}
+
+// CheckInvariants validates internal consistency of CircularBuffer:
+// 0 <= size <= capacity <= len(buffer), 0 <= shift < capacity
+// and every slot not holding an element is nil. With segmented storage
+// capacity is rounded up to whole blocks in these checks.
+// It is intended for tests and fuzzing.
+func (cb *CircularBuffer) CheckInvariants() error {
+	if cb.size < 0 || cb.size > cb.capacity || (cb.ring > cb.slots() && (cb.allocated() || cb.size > 0)) {
+		return fmt.Errorf("inconsistent size %d, capacity %d and backing array length %d",
+			cb.size, cb.capacity, cb.slots())
+	}
+	if cb.shift < 0 || (cb.ring > 0 && cb.shift >= cb.ring) || (cb.ring == 0 && cb.shift != 0) {
+		return fmt.Errorf("shift %d out of range for capacity %d", cb.shift, cb.capacity)
+	}
+	for _, block := range cb.blocks {
+		if len(block) != cb.blockSize {
+			return fmt.Errorf("block length %d differs from block size %d", len(block), cb.blockSize)
+		}
+	}
+	for slot := 0; slot < cb.slots(); slot++ {
+		if slot < cb.ring && (slot-cb.shift+cb.ring)%cb.ring < cb.size {
+			continue
+		}
+		if v := *cb.cell(slot); v != nil {
+			return fmt.Errorf("free slot %d holds %v", slot, v)
+		}
+	}
+	return nil
+}
+
+// Chunks returns an iterator over non-empty contiguous parts of CircularBuffer:
+// the ones returned by Slices, or a part per block with segmented storage.
+// Chunks are views like Slices. CircularBuffer must not be modified during iteration.
+func (cb *CircularBuffer) Chunks() iter.Seq[[]interface{}] {
+	return cb.runs(0, cb.size)
+}
+
 // Clear removes all the data from CircularBuffer.
 func (cb *CircularBuffer) Clear() {
-	for i := 0; i < cb.size; i++ {
-		cb.buffer[(cb.shift+i)%cb.capacity] = nil
-	}
+	cb.clearSlots(0, cb.size)
+	cb.seq += uint64(cb.size)
 	cb.size = 0
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+}
+
+// clearSlots zeroes n slots starting at the given distance from the front in bulk.
+func (cb *CircularBuffer) clearSlots(distance, n int) {
+	for run := range cb.runs(distance, n) {
+		clear(run)
+	}
+}
+
+// Clone returns a copy of CircularBuffer. Elements are copied shallowly.
+func (cb *CircularBuffer) Clone() CircularBuffer {
+	clone := *cb
+	clone.buffer = slices.Clone(cb.buffer)
+	if cb.blocks != nil {
+		clone.blocks = make([][]interface{}, len(cb.blocks))
+		for i, block := range cb.blocks {
+			clone.blocks[i] = slices.Clone(block)
+		}
+	}
+	clone.occupancy = slices.Clone(cb.occupancy)
+	return clone
+}
+
+// Compact replaces each run of equal consecutive elements of CircularBuffer
+// with a single copy like slices.Compact and returns the number of removed elements.
+// Elements are compared with ==, so they must be comparable.
+func (cb *CircularBuffer) Compact() int {
+	return cb.CompactFunc(func(a, b interface{}) bool { return a == b })
+}
+
+// CompactFunc is like Compact, but uses eq to compare elements.
+// For each run, the first element is kept.
+func (cb *CircularBuffer) CompactFunc(eq func(a, b interface{}) bool) int {
+	var last interface{}
+	first := true
+	return cb.filter(func(v interface{}) bool {
+		if !first && eq(last, v) {
+			return false
+		}
+		last, first = v, false
+		return true
+	})
+}
+
+// Contains checks if CircularBuffer has an element equal to value.
+func (cb *CircularBuffer) Contains(value interface{}) bool {
+	return cb.Index(value) >= 0
+}
+
+// ContainsFunc checks if CircularBuffer has an element for which pred holds.
+func (cb *CircularBuffer) ContainsFunc(pred func(interface{}) bool) bool {
+	return cb.IndexFunc(pred) >= 0
+}
+
+// CopyTo copies elements of CircularBuffer into dst from the front to the back
+// and returns the number of copied elements, which is the minimum of Size and len(dst).
+func (cb *CircularBuffer) CopyTo(dst []interface{}) int {
+	n := 0
+	for run := range cb.runs(0, min(cb.size, len(dst))) {
+		n += copy(dst[n:], run)
+	}
+	return n
+}
+
+// Delete removes elements in the range [i, j) from CircularBuffer,
+// which must satisfy 0 <= i <= j <= Size(). Elements on the shorter side
+// of the range are shifted. NextSeq is kept, as with RemoveAt.
+func (cb *CircularBuffer) Delete(i, j int) error {
+	if i < 0 || j < i || cb.size < j {
+		return ErrIndexOutOfRange
+	}
+	n := j - i
+	if i < cb.size-j {
+		for k := i - 1; k >= 0; k-- {
+			*cb.cell(cb.slot(k + n)) = *cb.cell(cb.slot(k))
+		}
+		cb.clearSlots(0, n)
+		cb.shift = cb.slot(n)
+	} else {
+		for k := j; k < cb.size; k++ {
+			*cb.cell(cb.slot(k - n)) = *cb.cell(cb.slot(k))
+		}
+		cb.clearSlots(cb.size-n, n)
+	}
+	cb.size = cb.size - n
+	cb.seq += uint64(n)
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+	return nil
+}
+
+// Count returns the number of elements of CircularBuffer equal to value.
+// Elements are compared with ==.
+func (cb *CircularBuffer) Count(value interface{}) int {
+	return cb.CountFunc(func(v interface{}) bool { return v == value })
+}
+
+// CountFunc returns the number of elements of CircularBuffer for which pred holds.
+func (cb *CircularBuffer) CountFunc(pred func(interface{}) bool) int {
+	n := 0
+	for v := range cb.Values() {
+		if pred(v) {
+			n++
+		}
+	}
+	return n
+}
+
+// DebugDump writes the internal state of CircularBuffer into w: capacity, shift and size,
+// then a row per slot of the backing array with its raw value, the logical index
+// stored in the slot (- for a free slot) and the logical element with the row number.
+func (cb *CircularBuffer) DebugDump(w io.Writer) error {
+	_, e := fmt.Fprintf(w, "capacity=%d shift=%d size=%d\n", cb.capacity, cb.shift, cb.size)
+	if e != nil {
+		return e
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "slot\traw\tindex\tlogical")
+	for slot := 0; slot < min(cb.ring, cb.slots()); slot++ {
+		index := "-"
+		if i := (slot - cb.shift + cb.ring) % cb.ring; i < cb.size {
+			index = fmt.Sprint(i)
+		}
+		fmt.Fprintf(tw, "%d\t%v\t%s", slot, *cb.cell(slot), index)
+		if v, e := cb.At(slot); e == nil {
+			fmt.Fprintf(tw, "\t%v", v)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// DiscardBack drops up to n back elements of CircularBuffer in bulk
+// and returns the number of dropped elements.
+func (cb *CircularBuffer) DiscardBack(n int) int {
+	if n < 0 {
+		panic("gocontainers: CircularBuffer.DiscardBack: negative count")
+	}
+	n = min(n, cb.size)
+	cb.clearSlots(cb.size-n, n)
+	cb.size = cb.size - n
+	cb.stats.PopBacks += uint64(n)
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+	return n
+}
+
+// DiscardFront drops up to n front elements of CircularBuffer in bulk
+// and returns the number of dropped elements.
+func (cb *CircularBuffer) DiscardFront(n int) int {
+	if n < 0 {
+		panic("gocontainers: CircularBuffer.DiscardFront: negative count")
+	}
+	n = min(n, cb.size)
+	cb.clearSlots(0, n)
+	cb.shift = cb.slot(n)
+	cb.size = cb.size - n
+	cb.seq += uint64(n)
+	cb.stats.PopFronts += uint64(n)
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+	return n
 }
 
 // Do calls function f on each element of the CircularBuffer.
@@ -72,103 +560,1481 @@ func (cb *CircularBuffer) Do(f func(interface{}) error) error {
 	return nil
 }
 
+// elementCodec returns Codec used to serialize elements.
+func (cb *CircularBuffer) elementCodec() Codec {
+	if cb.codec == nil {
+		return GobCodec{}
+	}
+	return cb.codec
+}
+
+// EnableOccupancyHistogram starts sampling the number of elements
+// after every modification of CircularBuffer.
+func (cb *CircularBuffer) EnableOccupancyHistogram() {
+	if cb.occupancy == nil {
+		cb.occupancy = make([]uint64, cb.capacity+1)
+	}
+}
+
+// evict passes value dropped by a push into full CircularBuffer to the eviction callback.
+func (cb *CircularBuffer) evict(value interface{}) {
+	if cb.onEvict != nil {
+		cb.onEvict(value)
+	}
+}
+
 // Empty checks if CircularBuffer has no elements.
 func (cb *CircularBuffer) Empty() bool {
 	return cb.size == 0
 }
 
+// Filter returns a new CircularBuffer of the same capacity with elements
+// of CircularBuffer for which pred holds in the same order.
+// CircularBuffer itself is left intact, unlike with Keep.
+func (cb *CircularBuffer) Filter(pred func(interface{}) bool) CircularBuffer {
+	f := NewCircularBuffer(cb.capacity)
+	for v := range cb.Values() {
+		if pred(v) {
+			*f.cell(f.size) = v
+			f.size++
+		}
+	}
+	f.updateMaxSize()
+	return f
+}
+
+// filter removes elements of CircularBuffer for which keep doesn't hold in a single pass
+// preserving the order of the rest and returns the number of removed elements.
+// NextSeq is kept, as with RemoveAt.
+func (cb *CircularBuffer) filter(keep func(interface{}) bool) int {
+	n := 0
+	for i := 0; i < cb.size; i++ {
+		if value := *cb.cell(cb.slot(i)); keep(value) {
+			*cb.cell(cb.slot(n)) = value
+			n++
+		}
+	}
+	removed := cb.size - n
+	cb.clearSlots(n, removed)
+	cb.size = n
+	cb.seq += uint64(removed)
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+	return removed
+}
+
+// Find returns the first element of CircularBuffer for which pred holds and its index.
+// It reports false if there is no such element.
+func (cb *CircularBuffer) Find(pred func(interface{}) bool) (interface{}, int, bool) {
+	for i, v := range cb.All() {
+		if pred(v) {
+			return v, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// FindLast returns the last element of CircularBuffer for which pred holds and its index.
+// It reports false if there is no such element.
+func (cb *CircularBuffer) FindLast(pred func(interface{}) bool) (interface{}, int, bool) {
+	for i, v := range cb.Backward() {
+		if pred(v) {
+			return v, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// format prints elements of CircularBuffer for String, GoString and Format.
+func (cb *CircularBuffer) format(header, verb, sep, footer string, indexed bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, header, cb.size, cb.capacity)
+	for i := 0; i < cb.size && i < maxFormatElements; i++ {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		if indexed {
+			fmt.Fprintf(&sb, "%d:", i)
+		}
+		v, _ := cb.At(i)
+		fmt.Fprintf(&sb, verb, v)
+	}
+	if cb.size > maxFormatElements {
+		fmt.Fprintf(&sb, "%s... %d more", sep, cb.size-maxFormatElements)
+	}
+	sb.WriteString(footer)
+	return sb.String()
+}
+
+// Format implements fmt.Formatter.
+// %v and %s print elements like String, %+v prefixes them with indices,
+// %#v prints the internal layout including the whole backing array,
+// or all blocks of segmented storage.
+func (cb CircularBuffer) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#') && cb.blockSize > 0:
+		fmt.Fprintf(f, "gocontainers.CircularBuffer{shift:%d, size:%d, capacity:%d, blocks:%#v}",
+			cb.shift, cb.size, cb.capacity, cb.blocks)
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "gocontainers.CircularBuffer{shift:%d, size:%d, capacity:%d, buffer:%#v}",
+			cb.shift, cb.size, cb.capacity, cb.buffer)
+	case verb == 'v' && f.Flag('+'):
+		io.WriteString(f, cb.format("CircularBuffer[len=%d cap=%d]{", "%v", " ", "}", true))
+	case verb == 'v' || verb == 's':
+		io.WriteString(f, cb.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(gocontainers.CircularBuffer=%s)", verb, cb.String())
+	}
+}
+
 // Front returns the front element in CircularBuffer.
 // In case of empty CircularBuffer nil returns.
 func (cb *CircularBuffer) Front() (interface{}, error) {
+	if cb.Empty() {
+		return nil, ErrEmpty
+	}
 	return cb.At(0)
 }
 
+// FrontPtr returns a pointer to the slot holding the front element, see AtPtr.
+func (cb *CircularBuffer) FrontPtr() (*interface{}, error) {
+	if cb.Empty() {
+		return nil, ErrEmpty
+	}
+	return cb.AtPtr(0)
+}
+
 // Full checks if CircularBuffer is full.
 func (cb *CircularBuffer) Full() bool {
 	return cb.size == cb.capacity
 }
 
-// PopBack removes back element from CircularBuffer.
-func (cb *CircularBuffer) PopBack() {
-	if !cb.Empty() {
-		cb.buffer[(cb.shift+cb.size-1)%cb.capacity] = nil
-		cb.size = cb.size - 1
+// GoString returns length, capacity and elements of CircularBuffer in Go syntax.
+// Only the first few elements are printed for large buffers.
+// Note that %#v verb prints the internal layout instead, see Format.
+func (cb CircularBuffer) GoString() string {
+	return cb.format("gocontainers.CircularBuffer{len:%d, cap:%d, elements:[]interface {}{", "%#v", ", ", "}}", false)
+}
+
+// Grow increases capacity of CircularBuffer, if necessary, to guarantee space
+// for another n elements, so the next n pushes don't overwrite anything.
+// If n is negative, Grow panics.
+func (cb *CircularBuffer) Grow(n int) {
+	if n < 0 {
+		panic("gocontainers: CircularBuffer.Grow: negative count")
+	}
+	if cb.size+n > cb.capacity {
+		cb.Resize(cb.size + n)
 	}
 }
 
-// PopFront removes front element from CircularBuffer.
-func (cb *CircularBuffer) PopFront() {
-	if !cb.Empty() {
-		cb.buffer[cb.shift%cb.capacity] = nil
-		cb.size = cb.size - 1
-		cb.shift = (cb.shift + 1) % cb.capacity
+// growFor enlarges CircularBuffer to fit n more elements if growth is enabled.
+// Capacity is at least doubled, but never exceeds the growth limit.
+func (cb *CircularBuffer) growFor(n int) {
+	if cb.growLimit == 0 || cb.size+n <= cb.capacity {
+		return
+	}
+	capacity := max(2*cb.capacity, cb.size+n)
+	if cb.growLimit > 0 {
+		capacity = min(capacity, cb.growLimit)
+	}
+	if capacity > cb.capacity {
+		cb.resize(capacity)
 	}
 }
 
-// PushBack appends new element into CircularBuffer.
-// If CircularBuffer is full, PopFront() will be called.
-func (cb *CircularBuffer) PushBack(value interface{}) {
-	if cb.Full() {
-		cb.PopFront()
+// Head returns a copy of the first n elements of CircularBuffer.
+// n is clamped to [0, Size()].
+func (cb *CircularBuffer) Head(n int) []interface{} {
+	head := make([]interface{}, min(max(n, 0), cb.size))
+	cb.CopyTo(head)
+	return head
+}
+
+// Index returns the index of the first element of CircularBuffer equal to value,
+// or -1 if there is no such element. Elements are compared with ==.
+func (cb *CircularBuffer) Index(value interface{}) int {
+	return cb.IndexFunc(func(v interface{}) bool { return v == value })
+}
+
+// IndexFunc returns the index of the first element of CircularBuffer for which pred holds,
+// or -1 if there is no such element.
+func (cb *CircularBuffer) IndexFunc(pred func(interface{}) bool) int {
+	n := 0
+	for run := range cb.runs(0, cb.size) {
+		if i := slices.IndexFunc(run, pred); i >= 0 {
+			return n + i
+		}
+		n += len(run)
 	}
-	cb.buffer[(cb.size+cb.shift)%cb.capacity] = value
-	cb.size = cb.size + 1
+	return -1
 }
 
-// PushFront appends new element into CircularBuffer.
-// If CircularBuffer is full, PopBack() will be called.
-func (cb *CircularBuffer) PushFront(value interface{}) {
+// InsertAt inserts value into CircularBuffer so that it gets the given index,
+// which must be in [0, Size()]. Elements on the shorter side of index are shifted.
+// If CircularBuffer is full, the element at the end farther from index is dropped
+// and returned, or value itself with the Discard policy.
+func (cb *CircularBuffer) InsertAt(index int, value interface{}) (evicted interface{}, overwritten bool, e error) {
+	if index < 0 || index > cb.size {
+		return nil, false, ErrIndexOutOfRange
+	}
+	cb.growFor(1)
+	cb.allocate()
 	if cb.Full() {
-		cb.PopBack()
+		cb.stats.Overwrites++
+		if cb.policy == Discard || cb.capacity == 0 {
+			cb.evict(value)
+			return value, true, nil
+		}
+		overwritten = true
+		if index < cb.size-index {
+			evicted = *cb.cell(cb.slot(cb.size - 1))
+			cb.popBack()
+		} else {
+			evicted = *cb.cell(cb.shift)
+			cb.popFront()
+			index--
+		}
+		cb.evict(evicted)
 	}
-	index := (cb.shift + cb.capacity - 1) % cb.capacity
-	cb.buffer[index] = value
-	cb.shift = index
+	if index < cb.size-index {
+		cb.shift = cb.slot(cb.ring - 1)
+		cb.seq--
+		for i := 0; i < index; i++ {
+			*cb.cell(cb.slot(i)) = *cb.cell(cb.slot(i + 1))
+		}
+	} else {
+		for i := cb.size; i > index; i-- {
+			*cb.cell(cb.slot(i)) = *cb.cell(cb.slot(i - 1))
+		}
+	}
+	*cb.cell(cb.slot(index)) = value
 	cb.size = cb.size + 1
+	cb.updateMaxSize()
+	cb.sampleOccupancy()
+	return evicted, overwritten, nil
 }
 
-// Resize affects capacity of CircularBuffer. TODO: Better algorithm.
-func (cb *CircularBuffer) Resize(size int) {
-	cb.shiftToZero()
-	if size > cb.size {
-		if len(cb.buffer) < size {
-			abuffer := make([]interface{}, size-len(cb.buffer))
-			cb.buffer = append(cb.buffer, abuffer...)
+// InsertSorted inserts value into CircularBuffer sorted by cmp keeping it sorted.
+// Value goes after equal elements. If CircularBuffer is full and cannot grow,
+// the element at the given end is dropped and returned, which may be value itself,
+// or value is discarded with the Discard policy.
+func (cb *CircularBuffer) InsertSorted(value interface{}, cmp func(a, b interface{}) int, end End) (evicted interface{}, overwritten bool) {
+	index := sort.Search(cb.size, func(i int) bool { return cmp(*cb.cell(cb.slot(i)), value) > 0 })
+	if cb.saturated() {
+		cb.stats.Overwrites++
+		switch {
+		case cb.policy == Discard || end == AtFront && index == 0 || end == AtBack && index == cb.size:
+			cb.evict(value)
+			return value, true
+		case end == AtFront:
+			evicted = *cb.cell(cb.shift)
+			cb.popFront()
+			index--
+		default:
+			evicted = *cb.cell(cb.slot(cb.size - 1))
+			cb.popBack()
 		}
-	} else {
-		cb.size = size
+		cb.evict(evicted)
+		overwritten = true
 	}
-	cb.capacity = size
+	cb.InsertAt(index, value)
+	return evicted, overwritten
 }
 
-// shiftToZero makes shift zero. TODO: Make private.
-func (cb *CircularBuffer) shiftToZero() {
-	var swap = func(i, j int) {
-		temp := cb.buffer[i]
-		cb.buffer[i] = cb.buffer[j]
-		cb.buffer[j] = temp
+// IsSortedFunc checks if elements of CircularBuffer are sorted by cmp
+// like slices.IsSortedFunc.
+func (cb *CircularBuffer) IsSortedFunc(cmp func(a, b interface{}) int) bool {
+	for i := 1; i < cb.size; i++ {
+		if cmp(*cb.cell(cb.slot(i)), *cb.cell(cb.slot(i - 1))) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Keep removes all elements of CircularBuffer for which pred doesn't hold
+// preserving the order of the rest and returns the number of removed elements.
+func (cb *CircularBuffer) Keep(pred func(interface{}) bool) int {
+	return cb.filter(pred)
+}
+
+// LastIndexFunc returns the index of the last element of CircularBuffer for which pred holds,
+// or -1 if there is no such element.
+func (cb *CircularBuffer) LastIndexFunc(pred func(interface{}) bool) int {
+	for i, v := range cb.Backward() {
+		if pred(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Map returns a new CircularBuffer of the same capacity with results of f
+// applied to elements of CircularBuffer in the same order.
+// Configuration of CircularBuffer is not copied, since it may not suit the results.
+func (cb *CircularBuffer) Map(f func(interface{}) interface{}) CircularBuffer {
+	m := NewCircularBuffer(cb.capacity)
+	for i, v := range cb.All() {
+		*m.cell(i) = f(v)
+	}
+	m.size = cb.size
+	m.updateMaxSize()
+	return m
+}
+
+// MarshalBinary encodes CircularBuffer like WriteSnapshot.
+func (cb CircularBuffer) MarshalBinary() ([]byte, error) {
+	return cb.Snapshot()
+}
+
+// MarshalJSON encodes elements of CircularBuffer as JSON array from the front to the back.
+func (cb CircularBuffer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cb.ToArray())
+}
+
+// MaxFunc returns the first maximal element of CircularBuffer and its index
+// using cmp to compare elements. It reports false if CircularBuffer is empty.
+func (cb *CircularBuffer) MaxFunc(cmp func(a, b interface{}) int) (interface{}, int, bool) {
+	return cb.MinFunc(func(a, b interface{}) int { return cmp(b, a) })
+}
+
+// maybeShrink halves capacity of CircularBuffer after an element removal
+// if the policy set by WithAutoShrink asks for it.
+func (cb *CircularBuffer) maybeShrink() {
+	p := &cb.shrink
+	if p.after == 0 || !cb.allocated() {
+		return
 	}
-	var revert = func(i, j int) {
-		for k := i; k < (i+j)/2; k++ {
-			swap(k, j+i-k-1)
+	if float64(cb.size) >= p.threshold*float64(cb.capacity) {
+		p.low = 0
+		return
+	}
+	p.low++
+	if p.low < p.after {
+		return
+	}
+	p.low = 0
+	if capacity := max(cb.capacity/2, cb.size, p.floor, 1); capacity < cb.capacity {
+		cb.reallocate(capacity)
+	}
+}
+
+// MinFunc returns the first minimal element of CircularBuffer and its index
+// using cmp to compare elements. It reports false if CircularBuffer is empty.
+func (cb *CircularBuffer) MinFunc(cmp func(a, b interface{}) int) (interface{}, int, bool) {
+	if cb.Empty() {
+		return nil, -1, false
+	}
+	value, index := *cb.cell(cb.shift), 0
+	for i, v := range cb.All() {
+		if cmp(v, value) < 0 {
+			value, index = v, i
 		}
 	}
-	revert(0, cb.shift)
-	revert(cb.shift, cb.capacity)
-	revert(0, cb.capacity)
-	cb.shift = 0
+	return value, index, true
 }
 
-// Size returns number of elements in CircularBuffer.
-func (cb *CircularBuffer) Size() int {
-	return cb.size
+// MustAt is like At but panics if index is out of range.
+func (cb *CircularBuffer) MustAt(index int) interface{} {
+	v, e := cb.At(index)
+	if e != nil {
+		panic(fmt.Errorf("gocontainers: MustAt(%d) on CircularBuffer of size %d: %w", index, cb.size, e))
+	}
+	return v
 }
 
-// ToArray converts CircularBuffer to Array. TODO: Better algorithm?
-func (cb *CircularBuffer) ToArray() []interface{} {
-	array := make([]interface{}, cb.size)
-	for i := 0; i < cb.size; i++ {
-		array[i], _ = cb.At(i)
+// MustBack is like Back but panics if CircularBuffer is empty.
+func (cb *CircularBuffer) MustBack() interface{} {
+	v, e := cb.Back()
+	if e != nil {
+		panic(fmt.Errorf("gocontainers: MustBack on CircularBuffer: %w", e))
+	}
+	return v
+}
+
+// MustFront is like Front but panics if CircularBuffer is empty.
+func (cb *CircularBuffer) MustFront() interface{} {
+	v, e := cb.Front()
+	if e != nil {
+		panic(fmt.Errorf("gocontainers: MustFront on CircularBuffer: %w", e))
+	}
+	return v
+}
+
+// MustPopFront removes the front element from CircularBuffer and returns it.
+// It panics if CircularBuffer is empty.
+func (cb *CircularBuffer) MustPopFront() interface{} {
+	v, e := cb.Front()
+	if e != nil {
+		panic(fmt.Errorf("gocontainers: MustPopFront on CircularBuffer: %w", e))
+	}
+	cb.PopFront()
+	return v
+}
+
+// NextSeq returns the sequence number which the next PushBack will assign.
+func (cb *CircularBuffer) NextSeq() uint64 {
+	return cb.seq + uint64(cb.size)
+}
+
+// OccupancyHistogram returns how many times each number of elements was sampled:
+// the i-th counter corresponds to i elements.
+// In case of disabled histogram nil returns.
+func (cb *CircularBuffer) OccupancyHistogram() []uint64 {
+	if cb.occupancy == nil {
+		return nil
+	}
+	histogram := make([]uint64, len(cb.occupancy))
+	copy(histogram, cb.occupancy)
+	return histogram
+}
+
+// Partition splits elements of CircularBuffer in one pass into a new CircularBuffer
+// with elements for which pred holds and another one with the rest.
+// Both have the capacity of CircularBuffer and keep the order of elements.
+func (cb *CircularBuffer) Partition(pred func(interface{}) bool) (match, rest CircularBuffer) {
+	match, rest = NewCircularBuffer(cb.capacity), NewCircularBuffer(cb.capacity)
+	for v := range cb.Values() {
+		part := &rest
+		if pred(v) {
+			part = &match
+		}
+		*part.cell(part.size) = v
+		part.size++
+	}
+	match.updateMaxSize()
+	rest.updateMaxSize()
+	return match, rest
+}
+
+// PopBack removes back element from CircularBuffer.
+func (cb *CircularBuffer) PopBack() {
+	if !cb.Empty() {
+		cb.popBack()
+		cb.stats.PopBacks++
+	}
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+}
+
+// popBack removes back element from non-empty CircularBuffer.
+func (cb *CircularBuffer) popBack() {
+	*cb.cell(cb.slot(cb.size - 1)) = nil
+	cb.size = cb.size - 1
+}
+
+// PopBackWhile removes back elements from CircularBuffer while pred holds for them
+// and returns the number of removed elements.
+func (cb *CircularBuffer) PopBackWhile(pred func(interface{}) bool) int {
+	n := 0
+	for n < cb.size && pred(*cb.cell(cb.slot(cb.size - 1 - n))) {
+		n++
+	}
+	return cb.DiscardBack(n)
+}
+
+// PopFront removes front element from CircularBuffer.
+func (cb *CircularBuffer) PopFront() {
+	if !cb.Empty() {
+		cb.popFront()
+		cb.stats.PopFronts++
+	}
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+}
+
+// popFront removes front element from non-empty CircularBuffer.
+func (cb *CircularBuffer) popFront() {
+	*cb.cell(cb.shift) = nil
+	cb.size = cb.size - 1
+	cb.shift = cb.slot(1)
+	cb.seq++
+}
+
+// PopFrontInto removes up to len(dst) front elements of CircularBuffer into dst
+// and returns the number of removed elements.
+func (cb *CircularBuffer) PopFrontInto(dst []interface{}) int {
+	n := cb.CopyTo(dst)
+	if n > 0 {
+		cb.clearSlots(0, n)
+		cb.size = cb.size - n
+		cb.shift = cb.slot(n)
+		cb.seq += uint64(n)
+		cb.stats.PopFronts += uint64(n)
+	}
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+	return n
+}
+
+// PopFrontWhile removes front elements from CircularBuffer while pred holds for them
+// and returns the number of removed elements.
+func (cb *CircularBuffer) PopFrontWhile(pred func(interface{}) bool) int {
+	n := 0
+	for n < cb.size && pred(*cb.cell(cb.slot(n))) {
+		n++
+	}
+	return cb.DiscardFront(n)
+}
+
+// PushBack appends new element into CircularBuffer.
+// If CircularBuffer is full, the front element is overwritten,
+// or value is discarded with the Discard policy or zero capacity.
+func (cb *CircularBuffer) PushBack(value interface{}) {
+	cb.growFor(1)
+	cb.allocate()
+	if cb.Full() {
+		cb.stats.Overwrites++
+		if cb.policy == Discard || cb.capacity == 0 {
+			cb.evict(value)
+			cb.stats.PushBacks++
+			cb.sampleOccupancy()
+			return
+		}
+		cb.evict(*cb.cell(cb.shift))
+		cb.popFront()
+	}
+	*cb.cell(cb.slot(cb.size)) = value
+	cb.size = cb.size + 1
+	cb.stats.PushBacks++
+	cb.updateMaxSize()
+	cb.sampleOccupancy()
+}
+
+// PushBackSlice appends elements of vs into CircularBuffer like PushBack in a loop,
+// but copies them in bulk. If vs is longer than capacity, only its tail is kept.
+// It returns the number of overwritten (or discarded) elements.
+func (cb *CircularBuffer) PushBackSlice(vs []interface{}) (overwritten int) {
+	cb.growFor(len(vs))
+	cb.allocate()
+	n := len(vs)
+	overwritten = max(0, cb.size+n-cb.capacity)
+	if cb.policy == Discard && overwritten > 0 {
+		free := n - overwritten
+		for _, v := range vs[free:] {
+			cb.evict(v)
+		}
+		cb.stats.PushBacks += uint64(overwritten)
+		cb.stats.Overwrites += uint64(overwritten)
+		return cb.PushBackSlice(vs[:free]) + overwritten
+	}
+	if cb.onEvict != nil {
+		for i := 0; i < overwritten; i++ {
+			if i < cb.size {
+				cb.onEvict(*cb.cell(cb.slot(i)))
+			} else {
+				cb.onEvict(vs[i-cb.size])
+			}
+		}
+	}
+	if n >= cb.capacity {
+		cb.clearSlots(0, cb.size)
+		cb.shift = 0
+		cb.store(0, vs[n-cb.capacity:])
+		cb.size = cb.capacity
+	} else {
+		drop := max(0, cb.size+n-cb.capacity)
+		cb.clearSlots(0, drop)
+		cb.shift = cb.slot(drop)
+		cb.size = cb.size - drop
+		cb.store(cb.size, vs)
+		cb.size = cb.size + n
+	}
+	cb.seq += uint64(overwritten)
+	cb.stats.PushBacks += uint64(n)
+	cb.stats.Overwrites += uint64(overwritten)
+	cb.updateMaxSize()
+	cb.sampleOccupancy()
+	return overwritten
+}
+
+// PushFront appends new element into CircularBuffer.
+// If CircularBuffer is full, the back element is overwritten,
+// or value is discarded with the Discard policy or zero capacity.
+func (cb *CircularBuffer) PushFront(value interface{}) {
+	cb.growFor(1)
+	cb.allocate()
+	if cb.Full() {
+		cb.stats.Overwrites++
+		if cb.policy == Discard || cb.capacity == 0 {
+			cb.evict(value)
+			cb.stats.PushFronts++
+			cb.sampleOccupancy()
+			return
+		}
+		cb.evict(*cb.cell(cb.slot(cb.size - 1)))
+		cb.popBack()
+	}
+	index := cb.slot(cb.ring - 1)
+	*cb.cell(index) = value
+	cb.shift = index
+	cb.size = cb.size + 1
+	cb.seq--
+	cb.stats.PushFronts++
+	cb.updateMaxSize()
+	cb.sampleOccupancy()
+}
+
+// PushFrontSlice prepends elements of vs into CircularBuffer keeping their order,
+// like PushFront in a loop from the last element to the first, but copies them in bulk.
+// If vs is longer than capacity, only its head is kept.
+// It returns the number of overwritten (or discarded) elements.
+func (cb *CircularBuffer) PushFrontSlice(vs []interface{}) (overwritten int) {
+	cb.growFor(len(vs))
+	cb.allocate()
+	n := len(vs)
+	overwritten = max(0, cb.size+n-cb.capacity)
+	if cb.policy == Discard && overwritten > 0 {
+		for i := overwritten - 1; i >= 0; i-- {
+			cb.evict(vs[i])
+		}
+		cb.stats.PushFronts += uint64(overwritten)
+		cb.stats.Overwrites += uint64(overwritten)
+		return cb.PushFrontSlice(vs[overwritten:]) + overwritten
+	}
+	if cb.onEvict != nil {
+		for i := 0; i < overwritten; i++ {
+			if i < cb.size {
+				cb.onEvict(*cb.cell(cb.slot(cb.size - 1 - i)))
+			} else {
+				cb.onEvict(vs[n-1-(i-cb.size)])
+			}
+		}
+	}
+	if n >= cb.capacity {
+		cb.clearSlots(0, cb.size)
+		cb.shift = 0
+		cb.store(0, vs[:cb.capacity])
+		cb.size = cb.capacity
+	} else {
+		for drop := max(0, cb.size+n-cb.capacity); drop > 0; drop-- {
+			cb.popBack()
+		}
+		cb.shift = cb.slot(cb.ring - n)
+		cb.store(0, vs)
+		cb.size = cb.size + n
+	}
+	cb.seq -= uint64(n)
+	cb.stats.PushFronts += uint64(n)
+	cb.stats.Overwrites += uint64(overwritten)
+	cb.updateMaxSize()
+	cb.sampleOccupancy()
+	return overwritten
+}
+
+// Raw returns the backing array of CircularBuffer with the physical index of
+// the front element and the number of elements. It is intended for advanced
+// use only, e.g. vectored I/O. Element i is stored at buf[(head+i)%len(buf)]
+// for 0 <= i < length, and all other slots of buf are nil.
+//
+// buf is shared with CircularBuffer. Writing elements in place with Set or
+// through buf keeps the view valid. Any other modification (pushes, pops,
+// Clear, Resize, Restore and anything built on them) may move elements or
+// replace the backing array, so head, length and buf must be fetched again.
+// Segmented storage has no backing array, so Raw panics with it.
+func (cb *CircularBuffer) Raw() (buf []interface{}, head, length int) {
+	if cb.blockSize > 0 {
+		panic("gocontainers: CircularBuffer.Raw: segmented storage")
+	}
+	cb.allocate()
+	return cb.buffer[:cb.capacity:cb.capacity], cb.shift, cb.size
+}
+
+// ReadCSV pushes rows of CSV stream r into the back of CircularBuffer,
+// so only the last rows are kept. The first row is skipped if header is true.
+// Each row is converted with parse, or stored as []string if parse is nil.
+func (cb *CircularBuffer) ReadCSV(r io.Reader, header bool, parse func([]string) (interface{}, error)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	for {
+		record, e := cr.Read()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+		if header {
+			header = false
+			continue
+		}
+		var v interface{} = record
+		if parse != nil {
+			v, e = parse(record)
+			if e != nil {
+				return e
+			}
+		}
+		cb.PushBack(v)
+	}
+}
+
+// ReadSnapshot replaces CircularBuffer with the snapshot read from r.
+// Snapshots of every format version written by WriteSnapshot or MarshalBinary are accepted,
+// as long as their capacity doesn't exceed MaxDecodedCapacity.
+// Codec of CircularBuffer is kept and used to decode elements.
+func (cb *CircularBuffer) ReadSnapshot(r io.Reader) error {
+	data, e := io.ReadAll(r)
+	if e != nil {
+		return e
+	}
+
+	br := bytes.NewReader(data)
+	var capacity, size, shift, seq uint64
+	if bytes.HasPrefix(data, snapshotMagic) {
+		br.Seek(int64(len(snapshotMagic)), io.SeekStart)
+		version, e := br.ReadByte()
+		if e != nil {
+			return e
+		}
+		if version != snapshotVersion {
+			return fmt.Errorf("unsupported snapshot version %d", version)
+		}
+		e = readUvarints(br, &capacity, &size, &shift, &seq)
+		if e != nil {
+			return e
+		}
+	} else {
+		version, e := br.ReadByte()
+		if e != nil {
+			return e
+		}
+		if version != legacyVersion {
+			return errors.New("not a snapshot")
+		}
+		e = readUvarints(br, &capacity, &size)
+		if e != nil {
+			return e
+		}
+	}
+	if capacity > MaxDecodedCapacity {
+		return fmt.Errorf("capacity %d exceeds MaxDecodedCapacity", capacity)
+	}
+	if size > capacity || size > uint64(br.Len()) {
+		return fmt.Errorf("inconsistent capacity %d and size %d", capacity, size)
+	}
+	if shift >= capacity && shift != 0 {
+		return fmt.Errorf("shift %d out of range for capacity %d", shift, capacity)
+	}
+
+	elements, e := readElements(br, size, cb.elementCodec())
+	if e != nil {
+		return e
+	}
+
+	cb.reset(int(capacity))
+	if len(elements) > 0 {
+		cb.allocate()
+	}
+	cb.shift = int(shift)
+	cb.seq = seq
+	for i, v := range elements {
+		*cb.cell(cb.slot(i)) = v
+	}
+	cb.size = len(elements)
+	return nil
+}
+
+// ReadSince returns elements with sequence numbers starting from seq
+// and the sequence number to pass into the next call.
+// Every element gets a sequence number when it is pushed to the back,
+// numbers grow monotonically as long as PushFront is not used.
+// If elements since seq were already removed, ReadSince starts from the front,
+// so len(elements) < next-seq reports a gap.
+func (cb *CircularBuffer) ReadSince(seq uint64) ([]interface{}, uint64) {
+	next := cb.NextSeq()
+	if seq >= next {
+		return nil, next
+	}
+	start := 0
+	if seq > cb.seq {
+		start = int(seq - cb.seq)
+	}
+	elements := make([]interface{}, 0, cb.size-start)
+	for i := start; i < cb.size; i++ {
+		v, _ := cb.At(i)
+		elements = append(elements, v)
+	}
+	return elements, next
+}
+
+// reallocate moves elements of CircularBuffer into a new backing array of given capacity,
+// which must not be less than the number of elements. Segmented storage drops
+// or adds blocks instead.
+func (cb *CircularBuffer) reallocate(capacity int) {
+	if cb.blockSize > 0 {
+		cb.resizeBlocks(capacity)
+		return
+	}
+	buffer := make([]interface{}, capacity)
+	cb.CopyTo(buffer)
+	cb.buffer = buffer
+	cb.setCapacity(capacity)
+	cb.shift = 0
+}
+
+// RemoveAt removes element from CircularBuffer by index and returns it.
+// Negative index counts from the back. Elements on the shorter side of index are shifted.
+// NextSeq is kept, so sequence numbers handed out by ReadSince stay valid.
+func (cb *CircularBuffer) RemoveAt(index int) (interface{}, error) {
+	index, ok := cb.resolve(index)
+	if !ok {
+		return nil, ErrIndexOutOfRange
+	}
+	value := *cb.cell(cb.slot(index))
+	if index < cb.size-1-index {
+		for i := index; i > 0; i-- {
+			*cb.cell(cb.slot(i)) = *cb.cell(cb.slot(i - 1))
+		}
+		cb.popFront()
+	} else {
+		for i := index; i < cb.size-1; i++ {
+			*cb.cell(cb.slot(i)) = *cb.cell(cb.slot(i + 1))
+		}
+		cb.popBack()
+		cb.seq++
+	}
+	cb.maybeShrink()
+	cb.sampleOccupancy()
+	return value, nil
+}
+
+// RemoveFunc removes all elements of CircularBuffer for which pred holds
+// preserving the order of the rest and returns the number of removed elements.
+func (cb *CircularBuffer) RemoveFunc(pred func(interface{}) bool) int {
+	return cb.filter(func(v interface{}) bool { return !pred(v) })
+}
+
+// Replace replaces elements in the range [i, j) of CircularBuffer with vs,
+// which must satisfy 0 <= i <= j <= Size(). If the result doesn't fit
+// into CircularBuffer, front elements are overwritten like with PushBackSlice,
+// or the last values of vs are discarded with the Discard policy.
+func (cb *CircularBuffer) Replace(i, j int, vs ...interface{}) error {
+	if i < 0 || j < i || cb.size < j {
+		return ErrIndexOutOfRange
+	}
+	m, n := len(vs), j-i
+	if m <= n {
+		for k, v := range vs {
+			*cb.cell(cb.slot(i + k)) = v
+		}
+		return cb.Delete(i+m, j)
+	}
+	cb.growFor(m - n)
+	cb.allocate()
+	overflow := max(0, cb.size+m-n-cb.capacity)
+	cb.stats.Overwrites += uint64(overflow)
+	if overflow > 0 && (cb.policy == Discard || cb.capacity == 0) {
+		for _, v := range vs[m-overflow:] {
+			cb.evict(v)
+		}
+		vs = vs[:m-overflow]
+		m, overflow = m-overflow, 0
+	}
+	tail := make([]interface{}, cb.size-j)
+	for k := range tail {
+		tail[k] = *cb.cell(cb.slot(j + k))
+	}
+	// The overflow may exceed the prefix and even the capacity: the whole
+	// prefix is evicted then, followed by the leading values of vs.
+	d := min(i, overflow)
+	for k := 0; k < d; k++ {
+		cb.evict(*cb.cell(cb.slot(k)))
+	}
+	for _, v := range vs[:overflow-d] {
+		cb.evict(v)
+	}
+	vs = vs[overflow-d:]
+	cb.clearSlots(0, d)
+	cb.shift = cb.slot(d)
+	p := i - d
+	for _, v := range vs {
+		*cb.cell(cb.slot(p)) = v
+		p++
+	}
+	for _, v := range tail {
+		*cb.cell(cb.slot(p)) = v
+		p++
+	}
+	cb.size = cb.size + m - n - overflow
+	cb.seq += uint64(overflow)
+	cb.updateMaxSize()
+	cb.sampleOccupancy()
+	return nil
+}
+
+// Reset returns CircularBuffer to the state right after NewCircularBuffer
+// keeping its backing array and configuration: it removes all the data
+// and zeroes statistics and the occupancy histogram. Sequence numbers keep
+// growing like after Clear, so ReadSince and Cursor see the removal.
+// Only slots holding elements are zeroed, in bulk, since elements are interface
+// values and may hold pointers.
+func (cb *CircularBuffer) Reset() {
+	cb.clearSlots(0, cb.size)
+	cb.shift = 0
+	cb.seq += uint64(cb.size)
+	cb.size = 0
+	cb.stats = Stats{}
+	cb.shrink.low = 0
+	clear(cb.occupancy)
+}
+
+// reset replaces CircularBuffer with an empty one of given capacity
+// keeping its configuration. The occupancy histogram starts over.
+func (cb *CircularBuffer) reset(capacity int) {
+	opts := []Option{WithCodec(cb.codec), WithOverwritePolicy(cb.policy),
+		WithEvictionCallback(cb.onEvict), withGrowLimit(cb.growLimit)}
+	if cb.pow2 {
+		opts = append(opts, WithPowerOfTwoCapacity())
+	}
+	if cb.lazy {
+		opts = append(opts, WithLazyAllocation())
+	}
+	if cb.occupancy != nil {
+		opts = append(opts, WithOccupancyHistogram())
+	}
+	if cb.blockSize > 0 {
+		opts = append(opts, WithSegmentedStorage(cb.blockSize))
+	}
+	shrink := cb.shrink
+	shrink.low = 0
+	*cb = NewCircularBuffer(capacity, opts...)
+	cb.shrink = shrink
+}
+
+// resolve converts negative index counting from the back into index counting
+// from the front and reports whether it refers to an element.
+func (cb *CircularBuffer) resolve(index int) (int, bool) {
+	if index < 0 {
+		index += cb.size
+	}
+	return index, 0 <= index && index < cb.size
+}
+
+// Restore replaces CircularBuffer with the snapshot taken by Snapshot.
+// Codec of CircularBuffer is kept and used to decode elements.
+func (cb *CircularBuffer) Restore(data []byte) error {
+	return cb.ReadSnapshot(bytes.NewReader(data))
+}
+
+// RestoreWithCapacity replaces CircularBuffer with the snapshot taken by Snapshot
+// like Restore, but sets the given capacity. If the snapshot has more elements,
+// only the back ones are kept.
+func (cb *CircularBuffer) RestoreWithCapacity(data []byte, capacity int) error {
+	snapshot := CircularBuffer{codec: cb.codec}
+	if e := snapshot.Restore(data); e != nil {
+		return e
+	}
+
+	cb.reset(capacity)
+	dropped := 0
+	if snapshot.size > cb.capacity {
+		dropped = snapshot.size - cb.capacity
+	}
+	if snapshot.size > dropped {
+		cb.allocate()
+	}
+	cb.seq = snapshot.seq + uint64(dropped)
+	for i := dropped; i < snapshot.size; i++ {
+		v, _ := snapshot.At(i)
+		*cb.cell(i - dropped) = v
+	}
+	cb.size = snapshot.size - dropped
+	return nil
+}
+
+// Resize affects capacity of CircularBuffer. TODO: Better algorithm.
+// When shrinking, back elements are dropped, see ResizeKeepBack.
+func (cb *CircularBuffer) Resize(size int) {
+	cb.resize(size)
+	cb.sampleOccupancy()
+}
+
+// resize changes capacity of CircularBuffer.
+func (cb *CircularBuffer) resize(size int) {
+	if !cb.allocated() {
+		cb.setCapacity(size)
+		return
+	}
+	if cb.blockSize > 0 {
+		cb.resizeBlocks(size)
+		return
+	}
+	if len(cb.buffer) < size {
+		cb.reallocate(size)
+		return
+	}
+	cb.shiftToZero()
+	if size < cb.size {
+		clear(cb.buffer[size:cb.size])
+		cb.size = size
+	}
+	cb.setCapacity(size)
+}
+
+// resizeBlocks changes capacity of CircularBuffer with segmented storage.
+// Blocks are rotated so that the front element is in the first one,
+// then blocks are added or dropped at the end. Only elements wrapping around
+// the end of the old or the new ring are moved, less than a block of them.
+func (cb *CircularBuffer) resizeBlocks(capacity int) {
+	if capacity < cb.size {
+		cb.clearSlots(capacity, cb.size-capacity)
+		cb.size = capacity
+	}
+	if cb.size == 0 {
+		cb.shift = 0
+	}
+	if b := cb.shift / cb.blockSize; b > 0 {
+		cb.blocks = slices.Concat(cb.blocks[b:], cb.blocks[:b])
+		cb.shift -= b * cb.blockSize
+	}
+	old := cb.ring
+	cb.setCapacity(capacity)
+	n := cb.ring / cb.blockSize
+	cb.addBlocks(n - len(cb.blocks))
+	if cb.ring != old {
+		for d := max(0, min(old, cb.ring)-cb.shift); d < cb.size; d++ {
+			from, to := cb.cell((cb.shift+d)%old), cb.cell((cb.shift+d)%cb.ring)
+			*to, *from = *from, nil
+		}
+	}
+	clear(cb.blocks[n:])
+	cb.blocks = cb.blocks[:n]
+}
+
+// ResizeKeepBack affects capacity of CircularBuffer like Resize,
+// but when shrinking it drops front (oldest) elements instead of back ones.
+func (cb *CircularBuffer) ResizeKeepBack(size int) {
+	for drop := cb.size - size; drop > 0; drop-- {
+		cb.popFront()
+	}
+	cb.Resize(size)
+}
+
+// runs returns an iterator over contiguous parts of storage holding n slots
+// starting at the given distance from the front: at most two parts of the backing
+// array, or a part per block with segmented storage. n must not exceed ring.
+func (cb *CircularBuffer) runs(distance, n int) iter.Seq[[]interface{}] {
+	return func(yield func([]interface{}) bool) {
+		slot := cb.slot(distance)
+		for n > 0 {
+			run := cb.buffer
+			offset := slot
+			if cb.blockSize > 0 {
+				run, offset = cb.blocks[slot/cb.blockSize], slot%cb.blockSize
+			}
+			k := min(n, cb.ring-slot, len(run)-offset)
+			if !yield(run[offset : offset+k : offset+k]) {
+				return
+			}
+			n -= k
+			slot += k
+			if slot == cb.ring {
+				slot = 0
+			}
+		}
+	}
+}
+
+// Sample returns k distinct elements of CircularBuffer chosen uniformly at random
+// using r as the source of randomness, in their order in CircularBuffer.
+// k is clamped to [0, Size()]. Indexes are chosen with Floyd's algorithm,
+// so only O(k) memory is used.
+func (cb *CircularBuffer) Sample(k int, r *rand.Rand) []interface{} {
+	k = min(max(k, 0), cb.size)
+	chosen := make(map[int]struct{}, k)
+	for j := cb.size - k; j < cb.size; j++ {
+		t := r.IntN(j + 1)
+		if _, ok := chosen[t]; ok {
+			t = j
+		}
+		chosen[t] = struct{}{}
+	}
+	indexes := make([]int, 0, k)
+	for i := range chosen {
+		indexes = append(indexes, i)
+	}
+	slices.Sort(indexes)
+	sample := make([]interface{}, k)
+	for i, index := range indexes {
+		sample[i] = *cb.cell(cb.slot(index))
+	}
+	return sample
+}
+
+// sampleOccupancy counts the current number of elements in the histogram.
+func (cb *CircularBuffer) sampleOccupancy() {
+	if cb.occupancy == nil {
+		return
+	}
+	for len(cb.occupancy) <= cb.size {
+		cb.occupancy = append(cb.occupancy, 0)
+	}
+	cb.occupancy[cb.size]++
+}
+
+// saturated checks if a push into CircularBuffer has to drop an element
+// because CircularBuffer is full and cannot grow.
+func (cb *CircularBuffer) saturated() bool {
+	return cb.Full() && (cb.growLimit == 0 || 0 < cb.growLimit && cb.growLimit <= cb.capacity)
+}
+
+// Set replaces element of CircularBuffer by index.
+// Negative index counts from the back.
+func (cb *CircularBuffer) Set(index int, value interface{}) error {
+	if index, ok := cb.resolve(index); ok {
+		*cb.cell(cb.slot(index)) = value
+		return nil
+	}
+	return ErrIndexOutOfRange
+}
+
+// setCapacity changes capacity of CircularBuffer, the ring length and the index mask.
+func (cb *CircularBuffer) setCapacity(capacity int) {
+	cb.capacity = capacity
+	cb.ring = capacity
+	if cb.blockSize > 0 {
+		cb.ring = (capacity + cb.blockSize - 1) / cb.blockSize * cb.blockSize
+	}
+	cb.mask = -1
+	if cb.ring > 0 && cb.ring&(cb.ring-1) == 0 {
+		cb.mask = cb.ring - 1
+	}
+}
+
+// SetCodec sets Codec used to serialize elements. Nil restores GobCodec.
+func (cb *CircularBuffer) SetCodec(codec Codec) {
+	cb.codec = codec
+}
+
+// shiftToZero moves elements of CircularBuffer to the beginning of the backing array.
+// Only the shorter of the two contiguous parts is copied through a temporary slice.
+func (cb *CircularBuffer) shiftToZero() {
+	if cb.shift == 0 {
+		return
+	}
+	first, second := cb.Slices()
+	if len(second) <= len(first) {
+		temp := slices.Clone(second)
+		copy(cb.buffer, first)
+		copy(cb.buffer[len(first):], temp)
+	} else {
+		temp := slices.Clone(first)
+		copy(cb.buffer[len(first):], second)
+		copy(cb.buffer, temp)
+	}
+	clear(cb.buffer[cb.size:cb.capacity])
+	cb.shift = 0
+}
+
+// ShrinkToFit reduces capacity of CircularBuffer to the number of elements,
+// but not below one, and releases the old backing array.
+func (cb *CircularBuffer) ShrinkToFit() {
+	cb.reallocate(max(cb.size, 1))
+	cb.sampleOccupancy()
+}
+
+// Shuffle randomizes the order of elements of CircularBuffer in place
+// with the Fisher-Yates shuffle using r as the source of randomness.
+func (cb *CircularBuffer) Shuffle(r *rand.Rand) {
+	r.Shuffle(cb.size, sortInterface{cb: cb}.Swap)
+}
+
+// Size returns number of elements in CircularBuffer.
+func (cb *CircularBuffer) Size() int {
+	return cb.size
+}
+
+// slot returns the index in the backing array of the element at the given distance
+// from the front. Distance must be in [0, ring], so a single subtraction
+// wraps the index around instead of a division.
+func (cb *CircularBuffer) slot(distance int) int {
+	if cb.mask >= 0 {
+		return (cb.shift + distance) & cb.mask
+	}
+	index := cb.shift + distance
+	if index >= cb.ring {
+		index -= cb.ring
+	}
+	return index
+}
+
+// Slices returns elements of CircularBuffer as at most two contiguous parts
+// of the backing array, first followed by second. Both are views, not copies:
+// they are valid only until the next modification of CircularBuffer.
+// Segmented storage has no backing array: first is a copy of all elements
+// and second is nil then, see Chunks for views.
+func (cb *CircularBuffer) Slices() (first, second []interface{}) {
+	if cb.blockSize > 0 {
+		return cb.ToArray(), nil
+	}
+	end := cb.shift + cb.size
+	if end <= cb.capacity {
+		return cb.buffer[cb.shift:end:end], nil
+	}
+	return cb.buffer[cb.shift:cb.capacity:cb.capacity], cb.buffer[: end-cb.capacity : end-cb.capacity]
+}
+
+// slots returns the number of slots in the backing array or segmented storage.
+func (cb *CircularBuffer) slots() int {
+	if cb.blockSize > 0 {
+		return len(cb.blocks) * cb.blockSize
+	}
+	return len(cb.buffer)
+}
+
+// Snapshot returns CircularBuffer encoded like WriteSnapshot.
+func (cb *CircularBuffer) Snapshot() ([]byte, error) {
+	var b bytes.Buffer
+	e := cb.WriteSnapshot(&b)
+	return b.Bytes(), e
+}
+
+// SortFunc sorts elements of CircularBuffer in place using cmp like slices.SortFunc.
+// Elements are moved to the beginning of the backing array first,
+// or sorted in a copy with segmented storage.
+func (cb *CircularBuffer) SortFunc(cmp func(a, b interface{}) int) {
+	if cb.blockSize > 0 {
+		sorted := cb.ToArray()
+		slices.SortFunc(sorted, cmp)
+		cb.store(0, sorted)
+		return
+	}
+	cb.shiftToZero()
+	slices.SortFunc(cb.buffer[:cb.size], cmp)
+}
+
+// SortInterface returns sort.Interface over elements of CircularBuffer ordered by less,
+// so it can be sorted in place with sort.Sort or sort.Stable.
+// Elements are not moved to the beginning of the backing array, unlike with SortFunc.
+func (cb *CircularBuffer) SortInterface(less func(a, b interface{}) bool) sort.Interface {
+	return sortInterface{cb: cb, less: less}
+}
+
+// SplitAt returns two new full CircularBuffers with elements of CircularBuffer
+// before and after the given index, which must be in [0, Size()].
+// CircularBuffer itself is left intact.
+func (cb *CircularBuffer) SplitAt(index int) (CircularBuffer, CircularBuffer, error) {
+	if index < 0 || index > cb.size {
+		return CircularBuffer{}, CircularBuffer{}, ErrIndexOutOfRange
+	}
+	return NewCircularBufferFromSlice(cb.Head(index)), NewCircularBufferFromSlice(cb.Tail(cb.size - index)), nil
+}
+
+// Stats returns cumulative counters of CircularBuffer.
+func (cb *CircularBuffer) Stats() Stats {
+	stats := cb.stats
+	stats.Size = cb.size
+	return stats
+}
+
+// store copies vs into slots starting at the given distance from the front.
+func (cb *CircularBuffer) store(distance int, vs []interface{}) {
+	for run := range cb.runs(distance, len(vs)) {
+		vs = vs[copy(run, vs):]
+	}
+}
+
+// String returns length, capacity and elements of CircularBuffer
+// from the front to the back, e.g. CircularBuffer[len=3 cap=5]{1 2 3}.
+// Only the first few elements are printed for large buffers.
+func (cb CircularBuffer) String() string {
+	return cb.format("CircularBuffer[len=%d cap=%d]{", "%v", " ", "}", false)
+}
+
+// Tail returns a copy of the last n elements of CircularBuffer.
+// n is clamped to [0, Size()].
+func (cb *CircularBuffer) Tail(n int) []interface{} {
+	tail := make([]interface{}, min(max(n, 0), cb.size))
+	t := tail
+	for run := range cb.runs(cb.size-len(tail), len(tail)) {
+		t = t[copy(t, run):]
+	}
+	return tail
+}
+
+// ToArray converts CircularBuffer to Array.
+func (cb *CircularBuffer) ToArray() []interface{} {
+	return cb.AppendTo(make([]interface{}, 0, cb.size))
+}
+
+// Truncate keeps only the first n elements of CircularBuffer and drops the rest
+// without moving elements. It does nothing if n is not less than Size.
+func (cb *CircularBuffer) Truncate(n int) {
+	if n < 0 {
+		panic("gocontainers: CircularBuffer.Truncate: negative count")
+	}
+	cb.DiscardBack(max(0, cb.size-n))
+}
+
+// TruncateBack keeps only the last n elements of CircularBuffer and drops the rest
+// without moving elements. It does nothing if n is not less than Size.
+func (cb *CircularBuffer) TruncateBack(n int) {
+	if n < 0 {
+		panic("gocontainers: CircularBuffer.TruncateBack: negative count")
+	}
+	cb.DiscardFront(max(0, cb.size-n))
+}
+
+// TryPushBack appends new element into CircularBuffer like PushBack,
+// but returns ErrFull instead of overwriting the front element or discarding value.
+func (cb *CircularBuffer) TryPushBack(value interface{}) error {
+	if cb.saturated() {
+		return ErrFull
+	}
+	cb.PushBack(value)
+	return nil
+}
+
+// TryPushFront appends new element into CircularBuffer like PushFront,
+// but returns ErrFull instead of overwriting the back element or discarding value.
+func (cb *CircularBuffer) TryPushFront(value interface{}) error {
+	if cb.saturated() {
+		return ErrFull
+	}
+	cb.PushFront(value)
+	return nil
+}
+
+// Unique removes all but the first occurrence of each element of CircularBuffer
+// preserving the order of the rest and returns the number of removed elements.
+// Elements are used as map keys, so they must be comparable.
+func (cb *CircularBuffer) Unique() int {
+	seen := make(map[interface{}]struct{}, cb.size)
+	return cb.filter(func(v interface{}) bool {
+		if _, ok := seen[v]; ok {
+			return false
+		}
+		seen[v] = struct{}{}
+		return true
+	})
+}
+
+// UnmarshalBinary replaces CircularBuffer like ReadSnapshot.
+func (cb *CircularBuffer) UnmarshalBinary(data []byte) error {
+	return cb.Restore(data)
+}
+
+// UnmarshalJSON replaces CircularBuffer with elements of JSON array.
+// It also accepts the document served by DebugHandler, whose "cap" sets capacity
+// up to MaxDecodedCapacity.
+// Otherwise the capacity is kept, or set to the number of elements if it is zero.
+// Only the last capacity elements are kept. Elements are decoded like into []interface{}.
+// JSON null leaves CircularBuffer unchanged.
+func (cb *CircularBuffer) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var state debugState
+	state.Cap = cb.capacity
+	var e error
+	if bytes.HasPrefix(data, []byte("{")) {
+		e = json.Unmarshal(data, &state)
+	} else {
+		e = json.Unmarshal(data, &state.Items)
+	}
+	if e != nil {
+		return e
+	}
+	if state.Cap < 0 {
+		return errors.New("negative capacity")
+	}
+	if state.Cap > MaxDecodedCapacity {
+		return fmt.Errorf("capacity %d exceeds MaxDecodedCapacity", state.Cap)
+	}
+	if state.Cap == 0 {
+		state.Cap = len(state.Items)
+	}
+
+	cb.reset(state.Cap)
+	if state.Cap == 0 {
+		return nil
+	}
+	for _, v := range state.Items {
+		cb.PushBack(v)
+	}
+	return nil
+}
+
+// UpdateAt runs f on the slot holding element of CircularBuffer by index,
+// so the element can be changed in place. Negative index counts from the back.
+// The pointer must not be retained after f returns.
+func (cb *CircularBuffer) UpdateAt(index int, f func(*interface{})) error {
+	p, e := cb.AtPtr(index)
+	if e != nil {
+		return e
+	}
+	f(p)
+	return nil
+}
+
+// updateMaxSize keeps track of the maximum number of elements.
+func (cb *CircularBuffer) updateMaxSize() {
+	if cb.size > cb.stats.MaxSize {
+		cb.stats.MaxSize = cb.size
+	}
+}
+
+// Values returns an iterator over elements of CircularBuffer from the front to the back.
+func (cb *CircularBuffer) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, v := range cb.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Window returns a view of elements in the range [i, j) of CircularBuffer,
+// which must satisfy 0 <= i <= j <= Size(). See Window for invalidation rules.
+func (cb *CircularBuffer) Window(i, j int) (Window, error) {
+	if i < 0 || j < i || cb.size < j {
+		return Window{}, ErrIndexOutOfRange
+	}
+	return Window{cb: cb, start: i, size: j - i}, nil
+}
+
+// WriteCSV writes elements of CircularBuffer into w as CSV from the front to the back.
+// The header row is written first unless it is nil. Each element is converted with row.
+func (cb *CircularBuffer) WriteCSV(w io.Writer, header []string, row func(interface{}) []string) error {
+	cw := csv.NewWriter(w)
+	if header != nil {
+		if e := cw.Write(header); e != nil {
+			return e
+		}
+	}
+	e := cb.Do(func(v interface{}) error {
+		return cw.Write(row(v))
+	})
+	if e != nil {
+		return e
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSnapshot writes CircularBuffer into w in a stable versioned format:
+// magic "GOCB", format version, capacity, number of elements, shift, sequence number
+// of the front element and length-prefixed elements from the front to the back.
+// Elements are encoded with GobCodec unless SetCodec was called.
+func (cb *CircularBuffer) WriteSnapshot(w io.Writer) error {
+	shift := cb.shift
+	if shift >= cb.capacity {
+		shift = 0 // segmented storage wraps around past capacity
+	}
+	b := append([]byte{}, snapshotMagic...)
+	b = append(b, snapshotVersion)
+	b = binary.AppendUvarint(b, uint64(cb.capacity))
+	b = binary.AppendUvarint(b, uint64(cb.size))
+	b = binary.AppendUvarint(b, uint64(shift))
+	b = binary.AppendUvarint(b, cb.seq)
+	b, e := appendElements(b, cb.ToArray(), cb.elementCodec())
+	if e != nil {
+		return e
 	}
-	return array
+	_, e = w.Write(b)
+	return e
 }
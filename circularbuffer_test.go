@@ -1,11 +1,226 @@
 package gocontainers
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
+func intSeq(n int) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// tickingClock returns a fake clock starting at 2020-01-01 UTC
+// which advances by a second on every call.
+func tickingClock() func() time.Time {
+	clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+}
+
+func TestNewCircularBufferFromSeq(t *testing.T) {
+	cb := NewCircularBufferFromSeq(intSeq(6), 4)
+	assert.Equal(t, cb.Capacity(), 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{2, 3, 4, 5})
+
+	cb = NewCircularBufferFromSeq(slices.Values([]interface{}{"a"}), 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{"a"})
+}
+
+func TestNewCircularBufferFromSlice(t *testing.T) {
+	cb := NewCircularBufferFromSlice([]interface{}{0, 1, 2})
+	assert.Equal(t, cb.Capacity(), 3)
+	assert.True(t, cb.Full())
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1, 2})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.PushBack(3) // [3 1 2]
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3})
+
+	cb = NewCircularBufferFromSlice(nil)
+	assert.Equal(t, cb.Capacity(), 0)
+	assert.True(t, cb.Empty())
+}
+
+func TestNewCircularBufferWithValues(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1)
+	assert.Equal(t, cb.Capacity(), 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1})
+
+	cb = NewCircularBufferWithValues(2, 0, 1, 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2})
+
+	cb = NewCircularBufferWithValues(2)
+	assert.True(t, cb.Empty())
+}
+
+func TestCompareFunc(t *testing.T) {
+	a := NewCircularBufferWithValues(2, "a", "B")
+	b := NewCircularBufferWithValues(2, "A", "b")
+	fold := func(x, y interface{}) int {
+		return strings.Compare(strings.ToLower(x.(string)), strings.ToLower(y.(string)))
+	}
+
+	assert.Equal(t, CompareFunc(&a, &b, fold), 0)
+	b.PushBack("c") // [c b]
+	assert.Equal(t, CompareFunc(&a, &b, fold), -1)
+	assert.Equal(t, CompareFunc(&b, &a, fold), 1)
+
+	empty := NewCircularBuffer(0)
+	assert.Equal(t, CompareFunc(&empty, &a, fold), -1)
+	assert.Equal(t, CompareFunc(&empty, &empty, fold), 0)
+}
+
+func TestInterleave(t *testing.T) {
+	a := NewCircularBufferWithValues(3, "a0", "a1", "a2")
+	b := NewCircularBuffer(2)
+	c := NewCircularBufferWithValues(2, "c0")
+
+	b.PushBack("b1")  // [b1 _]
+	b.PushFront("b0") // [b1 b0]
+
+	m := Interleave(&a, &b, &c)
+	assert.Equal(t, m.ToArray(), []interface{}{"a0", "b0", "c0", "a1", "b1", "a2"})
+	assert.Equal(t, m.Capacity(), 7)
+	assert.Nil(t, m.CheckInvariants())
+
+	m = Interleave()
+	assert.True(t, m.Empty())
+}
+
+func TestMergeSorted(t *testing.T) {
+	a := NewCircularBuffer(3)
+	b := NewCircularBuffer(2)
+	byNumber := func(x, y interface{}) int { return int(x.(float64)) - int(y.(float64)) }
+
+	m := MergeSorted(&a, &b, byNumber)
+	assert.True(t, m.Empty())
+	assert.Equal(t, m.Capacity(), 5)
+
+	a.PushBack(1.0)  // [1 _ _]
+	a.PushBack(3.0)  // [1 3 _]
+	a.PushFront(0.0) // [1 3 0]
+	b.PushBack(1.5)  // [1.5 _]
+	b.PushBack(4.0)  // [1.5 4]
+
+	m = MergeSorted(&a, &b, byNumber)
+	assert.Equal(t, m.ToArray(), []interface{}{0.0, 1.0, 1.5, 3.0, 4.0})
+	assert.Nil(t, m.CheckInvariants())
+}
+
+func TestCircularBufferAll(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+
+	var is []int
+	var vs []interface{}
+	for i, v := range cb.All() {
+		is = append(is, i)
+		vs = append(vs, v)
+	}
+	assert.Equal(t, is, []int{0, 1, 2, 3})
+	assert.Equal(t, vs, []interface{}{1, 2, 3, 4})
+
+	for i := range cb.All() {
+		assert.Equal(t, i, 0)
+		break
+	}
+}
+
+func TestCircularBufferAllPtr(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+
+	for i, p := range cb.AllPtr() {
+		*p = (*p).(int) * 10
+		if i == 2 {
+			break
+		}
+	}
+	assert.Equal(t, cb.ToArray(), []interface{}{10, 20, 30, 4})
+}
+
+func TestCircularBufferAppendBuffer(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	other := NewCircularBuffer(3)
+
+	cb.PushBack(0)     // [0 _ _ _]
+	other.PushBack(1)  // [1 _ _]
+	other.PushBack(2)  // [1 2 _]
+	other.PushFront(3) // [1 2 3]
+
+	assert.Equal(t, cb.AppendBuffer(&other), 0)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 3, 1, 2})
+	assert.Equal(t, other.ToArray(), []interface{}{3, 1, 2})
+
+	assert.Equal(t, cb.AppendBuffer(&other), 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{2, 3, 1, 2})
+
+	cb.PopFront()
+	cb.PopFront()
+	assert.Equal(t, cb.AppendBuffer(&cb), 0)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 1, 2})
+	assert.Nil(t, cb.CheckInvariants())
+}
+
+func TestCircularBufferAppendSeq(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack("a") // [a _ _ _]
+
+	cb.AppendSeq(intSeq(2)) // [a 0 1 _]
+	assert.Equal(t, cb.ToArray(), []interface{}{"a", 0, 1})
+
+	cb.AppendSeq(intSeq(3)) // [1 0 1 2]
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 0, 1, 2})
+}
+
+func TestCircularBufferAppendTo(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	dst := cb.AppendTo(nil)
+	assert.Empty(t, dst)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	dst = cb.AppendTo([]interface{}{"a"})
+	assert.Equal(t, dst, []interface{}{"a", 0, 1})
+
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	dst = make([]interface{}, 0, 8)
+	out := cb.AppendTo(dst)
+	assert.Equal(t, out, []interface{}{1, 2, 3, 4})
+	assert.Equal(t, &out[:1][0], &dst[:1][0])
+}
+
 func TestCircularBufferAt(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
@@ -17,8 +232,16 @@ func TestCircularBufferAt(t *testing.T) {
 	cb.PushBack(5) // [2 3 4 5]
 
 	v, e := cb.At(-1)
+	assert.Equal(t, v, 5)
+	assert.Nil(t, e)
+
+	v, e = cb.At(-4)
+	assert.Equal(t, v, 2)
+	assert.Nil(t, e)
+
+	v, e = cb.At(-5)
 	assert.Nil(t, v)
-	assert.NotNil(t, e)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
 
 	v, e = cb.At(0)
 	assert.Equal(t, v, 2)
@@ -38,7 +261,32 @@ func TestCircularBufferAt(t *testing.T) {
 
 	v, e = cb.At(4)
 	assert.Nil(t, v)
-	assert.NotNil(t, e)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+}
+
+func TestCircularBufferAtPtr(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+
+	p, e := cb.AtPtr(3)
+	assert.Nil(t, e)
+	assert.Equal(t, *p, 4)
+
+	*p = 5 // [5 1 2 3]
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3, 5})
+
+	p, e = cb.AtPtr(-4)
+	assert.Nil(t, e)
+	assert.Equal(t, *p, 1)
+
+	p, e = cb.AtPtr(4)
+	assert.Nil(t, p)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
 }
 
 func TestCircularBufferBack(t *testing.T) {
@@ -46,7 +294,7 @@ func TestCircularBufferBack(t *testing.T) {
 
 	v, e := cb.Back()
 	assert.Nil(t, v)
-	assert.NotNil(t, e)
+	assert.ErrorIs(t, e, ErrEmpty)
 
 	cb.PushBack(0) // [0 _ _ _]
 	cb.PushBack(1) // [0 1 _ _]
@@ -65,11 +313,93 @@ func TestCircularBufferBack(t *testing.T) {
 	assert.Nil(t, e)
 }
 
+func TestCircularBufferBackPtr(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	p, e := cb.BackPtr()
+	assert.Nil(t, p)
+	assert.ErrorIs(t, e, ErrEmpty)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+
+	p, e = cb.BackPtr()
+	assert.Nil(t, e)
+	*p = 2 // [0 2 _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 2})
+}
+
+func TestCircularBufferBackward(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+
+	var is []int
+	var vs []interface{}
+	for i, v := range cb.Backward() {
+		is = append(is, i)
+		vs = append(vs, v)
+	}
+	assert.Equal(t, is, []int{3, 2, 1, 0})
+	assert.Equal(t, vs, []interface{}{4, 3, 2, 1})
+}
+
 func TestCircularBufferCapacity(t *testing.T) {
 	cb := NewCircularBuffer(4)
 	assert.Equal(t, cb.Capacity(), 4)
 }
 
+func TestCircularBufferCheckInvariants(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [2 0 1 _]
+	cb.PopBack()    // [2 0 _ _]
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.Resize(1) // [2]
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.Resize(0) // []
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb = NewCircularBuffer(4)
+	cb.buffer[2] = 0
+	assert.EqualError(t, cb.CheckInvariants(), "free slot 2 holds 0")
+
+	cb = NewCircularBuffer(4)
+	cb.shift = 4
+	assert.EqualError(t, cb.CheckInvariants(), "shift 4 out of range for capacity 4")
+
+	cb = NewCircularBuffer(4)
+	cb.size = 5
+	assert.EqualError(t, cb.CheckInvariants(), "inconsistent size 5, capacity 4 and backing array length 4")
+}
+
+func TestCircularBufferChunks(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Empty(t, slices.Collect(cb.Chunks()))
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	assert.Equal(t, slices.Collect(cb.Chunks()), [][]interface{}{{0, 1}})
+
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	assert.Equal(t, slices.Collect(cb.Chunks()), [][]interface{}{{1, 2, 3}, {4}})
+
+	for chunk := range cb.Chunks() {
+		assert.Equal(t, chunk, []interface{}{1, 2, 3})
+		break
+	}
+}
+
 func TestCircularBufferClear(t *testing.T) {
 	cb := NewCircularBuffer(4)
 	assert.Zero(t, cb.Size())
@@ -88,6 +418,205 @@ func TestCircularBufferClear(t *testing.T) {
 	assert.Zero(t, cb.Size())
 }
 
+func TestCircularBufferClone(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+
+	clone := cb.Clone()
+	assert.Equal(t, clone.ToArray(), []interface{}{0, 1})
+	assert.Equal(t, clone.Stats(), cb.Stats())
+
+	clone.PushBack(2) // [0 1 2 _]
+	clone.Set(0, 3)   // [3 1 2 _]
+	assert.Equal(t, clone.ToArray(), []interface{}{3, 1, 2})
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1})
+}
+
+func TestCircularBufferCompact(t *testing.T) {
+	cb := NewCircularBuffer(6)
+
+	cb.PushBack(1)    // [1 _ _ _ _ _]
+	cb.PushBack(1)    // [1 1 _ _ _ _]
+	cb.PushBack(2)    // [1 1 2 _ _ _]
+	cb.PushBack(nil)  // [1 1 2 nil _ _]
+	cb.PushFront(nil) // [1 1 2 nil _ nil]
+	cb.PushFront(1)   // [1 1 2 nil 1 nil]
+
+	assert.Equal(t, cb.Compact(), 1)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, nil, 1, 2, nil})
+	assert.Equal(t, cb.Compact(), 0)
+
+	cb = NewCircularBufferWithValues(4, 1, 1, 1, 1)
+	assert.Equal(t, cb.Compact(), 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{1})
+}
+
+func TestCircularBufferCompactFunc(t *testing.T) {
+	cb := NewCircularBufferWithValues(6, "a", "A", "b", "B", "b", "a")
+
+	assert.Equal(t, cb.CompactFunc(func(a, b interface{}) bool { return strings.EqualFold(a.(string), b.(string)) }), 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{"a", "b", "a"})
+	assert.Equal(t, cb.NextSeq(), uint64(6))
+	assert.Nil(t, cb.CheckInvariants())
+}
+
+func TestCircularBufferContains(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.False(t, cb.Contains(nil))
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushFront(1) // [0 _ _ 1]
+
+	assert.True(t, cb.Contains(0))
+	assert.True(t, cb.Contains(1))
+	assert.False(t, cb.Contains(2))
+	assert.False(t, cb.Contains(nil))
+}
+
+func TestCircularBufferContainsFunc(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	negative := func(v interface{}) bool { return v.(int) < 0 }
+	assert.False(t, cb.ContainsFunc(negative))
+
+	cb.PushBack(0) // [0 _ _ _]
+	assert.False(t, cb.ContainsFunc(negative))
+	cb.PushFront(-1) // [0 _ _ -1]
+	assert.True(t, cb.ContainsFunc(negative))
+}
+
+func TestCircularBufferCopyTo(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	cb.PushBack(5) // [4 5 2 3]
+
+	dst := make([]interface{}, 6)
+	n := cb.CopyTo(dst)
+	assert.Equal(t, n, 4)
+	assert.Equal(t, dst, []interface{}{2, 3, 4, 5, nil, nil})
+
+	dst = make([]interface{}, 3)
+	n = cb.CopyTo(dst)
+	assert.Equal(t, n, 3)
+	assert.Equal(t, dst, []interface{}{2, 3, 4})
+
+	n = cb.CopyTo(nil)
+	assert.Equal(t, n, 0)
+}
+
+func TestCircularBufferCount(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Equal(t, cb.Count(0), 0)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(0) // [0 1 _ 0]
+
+	assert.Equal(t, cb.Count(0), 2)
+	assert.Equal(t, cb.Count(1), 1)
+	assert.Equal(t, cb.Count(nil), 0)
+}
+
+func TestCircularBufferCountFunc(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, errors.New("a"), nil, errors.New("b"), nil)
+
+	assert.Equal(t, cb.CountFunc(func(v interface{}) bool { return v != nil }), 2)
+	assert.Equal(t, cb.CountFunc(func(interface{}) bool { return false }), 0)
+}
+
+func TestCircularBufferDebugDump(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [1 2 3 4]
+	cb.PopBack()   // [1 2 3 _]
+
+	var b strings.Builder
+	e := cb.DebugDump(&b)
+	assert.Nil(t, e)
+	assert.Equal(t, b.String(), `capacity=4 shift=1 size=3
+slot  raw    index  logical
+0     <nil>  -      1
+1     1      0      2
+2     2      1      3
+3     3      2
+`)
+}
+
+func TestCircularBufferDelete(t *testing.T) {
+	cb := NewCircularBuffer(6)
+
+	cb.PushBack(0)  // [0 _ _ _ _ _]
+	cb.PushBack(1)  // [0 1 _ _ _ _]
+	cb.PushBack(2)  // [0 1 2 _ _ _]
+	cb.PushBack(3)  // [0 1 2 3 _ _]
+	cb.PushFront(4) // [0 1 2 3 _ 4]
+	cb.PushFront(5) // [0 1 2 3 5 4]
+
+	assert.Nil(t, cb.Delete(1, 3)) // [5 1 2 3 _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 1, 2, 3})
+	assert.Equal(t, cb.buffer, []interface{}{5, 1, 2, 3, nil, nil})
+	assert.Nil(t, cb.CheckInvariants())
+
+	assert.Nil(t, cb.Delete(2, 3)) // [5 1 3 _ _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 1, 3})
+	assert.Equal(t, cb.buffer, []interface{}{5, 1, 3, nil, nil, nil})
+	assert.Equal(t, cb.NextSeq(), uint64(4))
+
+	assert.Nil(t, cb.Delete(1, 1))
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 1, 3})
+
+	assert.ErrorIs(t, cb.Delete(2, 4), ErrIndexOutOfRange)
+	assert.ErrorIs(t, cb.Delete(-1, 1), ErrIndexOutOfRange)
+	assert.ErrorIs(t, cb.Delete(2, 1), ErrIndexOutOfRange)
+
+	assert.Nil(t, cb.Delete(0, 3))
+	assert.True(t, cb.Empty())
+	assert.Equal(t, cb.buffer, make([]interface{}, 6))
+}
+
+func TestCircularBufferDiscardBack(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	assert.Equal(t, cb.DiscardBack(2), 2) // [_ _ _ 2]
+	assert.Equal(t, cb.ToArray(), []interface{}{2})
+	assert.Equal(t, cb.buffer, []interface{}{nil, nil, nil, 2})
+	assert.Equal(t, cb.DiscardBack(0), 0)
+	assert.Equal(t, cb.DiscardBack(3), 1)
+	assert.True(t, cb.Empty())
+	assert.Equal(t, cb.Stats().PopBacks, uint64(3))
+	assert.PanicsWithValue(t, "gocontainers: CircularBuffer.DiscardBack: negative count", func() { cb.DiscardBack(-1) })
+}
+
+func TestCircularBufferDiscardFront(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	assert.Equal(t, cb.DiscardFront(2), 2) // [_ 1 _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{1})
+	assert.Equal(t, cb.buffer, []interface{}{nil, 1, nil, nil})
+	assert.Equal(t, cb.NextSeq(), uint64(2))
+	assert.Equal(t, cb.DiscardFront(3), 1)
+	assert.True(t, cb.Empty())
+	assert.Equal(t, cb.Stats().PopFronts, uint64(3))
+	assert.PanicsWithValue(t, "gocontainers: CircularBuffer.DiscardFront: negative count", func() { cb.DiscardFront(-1) })
+}
+
 func TestCircularBufferDo(t *testing.T) {
 	testMap := make(map[int]bool)
 
@@ -143,12 +672,86 @@ func TestCircularBufferEmpty(t *testing.T) {
 	assert.True(t, cb.Empty())
 }
 
+func TestCircularBufferFilter(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(2)  // [1 2 _ _]
+	cb.PushFront(3) // [1 2 _ 3]
+
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	f := cb.Filter(odd)
+	assert.Equal(t, f.ToArray(), []interface{}{3, 1})
+	assert.Equal(t, f.Capacity(), 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 1, 2})
+	assert.Nil(t, f.CheckInvariants())
+
+	f = cb.Filter(func(interface{}) bool { return false })
+	assert.True(t, f.Empty())
+}
+
+func TestCircularBufferFind(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	_, index, ok := cb.Find(odd)
+	assert.Equal(t, index, -1)
+	assert.False(t, ok)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(3)  // [1 3 _ _]
+	cb.PushFront(2) // [1 3 _ 2]
+
+	value, index, ok := cb.Find(odd)
+	assert.Equal(t, value, 1)
+	assert.Equal(t, index, 1)
+	assert.True(t, ok)
+	_, _, ok = cb.Find(func(v interface{}) bool { return v.(int) > 3 })
+	assert.False(t, ok)
+}
+
+func TestCircularBufferFindLast(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	even := func(v interface{}) bool { return v.(int)%2 == 0 }
+	_, index, ok := cb.FindLast(even)
+	assert.Equal(t, index, -1)
+	assert.False(t, ok)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushFront(2) // [1 _ _ 2]
+	cb.PushFront(4) // [1 _ 4 2]
+
+	value, index, ok := cb.FindLast(even)
+	assert.Equal(t, value, 2)
+	assert.Equal(t, index, 1)
+	assert.True(t, ok)
+	_, _, ok = cb.FindLast(func(v interface{}) bool { return v.(int) > 4 })
+	assert.False(t, ok)
+}
+
+func TestCircularBufferFormat(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [1 2 3 4]
+	cb.PopBack()   // [1 2 3 _]
+
+	assert.Equal(t, fmt.Sprintf("%v", cb), "CircularBuffer[len=3 cap=4]{1 2 3}")
+	assert.Equal(t, fmt.Sprintf("%s", &cb), "CircularBuffer[len=3 cap=4]{1 2 3}")
+	assert.Equal(t, fmt.Sprintf("%+v", cb), "CircularBuffer[len=3 cap=4]{0:1 1:2 2:3}")
+	assert.Equal(t, fmt.Sprintf("%#v", cb),
+		"gocontainers.CircularBuffer{shift:1, size:3, capacity:4, buffer:[]interface {}{interface {}(nil), 1, 2, 3}}")
+	assert.Equal(t, fmt.Sprintf("%d", cb), "%!d(gocontainers.CircularBuffer=CircularBuffer[len=3 cap=4]{1 2 3})")
+}
+
 func TestCircularBufferFront(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
 	v, e := cb.Front()
 	assert.Nil(t, v)
-	assert.NotNil(t, e)
+	assert.ErrorIs(t, e, ErrEmpty)
 
 	cb.PushBack(0) // [0 _ _ _]
 	cb.PushBack(1) // [0 1 _ _]
@@ -167,6 +770,22 @@ func TestCircularBufferFront(t *testing.T) {
 	assert.Nil(t, e)
 }
 
+func TestCircularBufferFrontPtr(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	p, e := cb.FrontPtr()
+	assert.Nil(t, p)
+	assert.ErrorIs(t, e, ErrEmpty)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+
+	p, e = cb.FrontPtr()
+	assert.Nil(t, e)
+	*p = 2 // [2 1 _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{2, 1})
+}
+
 func TestCircularBufferFull(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
@@ -183,12 +802,413 @@ func TestCircularBufferFull(t *testing.T) {
 	assert.True(t, cb.Full())
 }
 
-func TestCircularBufferPopBack(t *testing.T) {
+func TestCircularBufferGoString(t *testing.T) {
 	cb := NewCircularBuffer(4)
+	assert.Equal(t, cb.GoString(), "gocontainers.CircularBuffer{len:0, cap:4, elements:[]interface {}{}}")
 
-	cb.PushBack(0) // [0 _ _ _]
-	cb.PushBack(1) // [0 1 _ _]
-	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(0)   // [0 _ _ _]
+	cb.PushBack("1") // [0 "1" _ _]
+	assert.Equal(t, cb.GoString(), `gocontainers.CircularBuffer{len:2, cap:4, elements:[]interface {}{0, "1"}}`)
+
+	cb = NewCircularBuffer(maxFormatElements + 2)
+	for i := 0; i < maxFormatElements+2; i++ {
+		cb.PushBack(i)
+	}
+	assert.True(t, strings.HasSuffix(cb.GoString(), "30, 31, ... 2 more}}"))
+}
+
+func TestCircularBufferGrow(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+
+	cb.Grow(0)
+	assert.Equal(t, cb.Capacity(), 4)
+
+	cb.Grow(2) // [1 2 3 4 _ _]
+	assert.Equal(t, cb.Capacity(), 6)
+	cb.PushBack(5) // [1 2 3 4 5 _]
+	cb.PushBack(6) // [1 2 3 4 5 6]
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3, 4, 5, 6})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(1))
+
+	cb.PopFront() // [_ 2 3 4 5 6]
+	cb.Grow(1)
+	assert.Equal(t, cb.Capacity(), 6)
+
+	assert.Panics(t, func() {
+		cb.Grow(-1)
+	})
+}
+
+func TestCircularBufferHead(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Equal(t, cb.Head(2), []interface{}{})
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	assert.Equal(t, cb.Head(2), []interface{}{2, 0})
+	assert.Equal(t, cb.Head(5), []interface{}{2, 0, 1})
+	assert.Equal(t, cb.Head(-1), []interface{}{})
+}
+
+func TestCircularBufferIndex(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Equal(t, cb.Index(0), -1)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushBack(0)  // [0 1 0 _]
+	cb.PushFront(2) // [0 1 0 2]
+
+	assert.Equal(t, cb.Index(2), 0)
+	assert.Equal(t, cb.Index(0), 1)
+	assert.Equal(t, cb.Index(1), 2)
+	assert.Equal(t, cb.Index("0"), -1)
+}
+
+func TestCircularBufferIndexFunc(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	assert.Equal(t, cb.IndexFunc(odd), -1)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushBack(3)  // [0 1 3 _]
+	cb.PushFront(2) // [0 1 3 2]
+
+	assert.Equal(t, cb.IndexFunc(odd), 2)
+	cb.PushFront(5) // [0 1 5 2]
+	assert.Equal(t, cb.IndexFunc(odd), 0)
+	assert.Equal(t, cb.IndexFunc(func(v interface{}) bool { return v.(int) > 5 }), -1)
+}
+
+func TestCircularBufferInsertAt(t *testing.T) {
+	cb := NewCircularBuffer(5)
+
+	cb.PushBack(0) // [0 _ _ _ _]
+	cb.PushBack(2) // [0 2 _ _ _]
+	cb.PushBack(3) // [0 2 3 _ _]
+
+	evicted, overwritten, e := cb.InsertAt(1, 1) // [1 2 3 _ 0]
+	assert.Nil(t, evicted)
+	assert.False(t, overwritten)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1, 2, 3})
+	assert.Equal(t, cb.buffer, []interface{}{1, 2, 3, nil, 0})
+
+	_, _, e = cb.InsertAt(3, 4) // [1 2 4 3 0]
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1, 2, 4, 3})
+	assert.Nil(t, cb.CheckInvariants())
+
+	evicted, overwritten, e = cb.InsertAt(1, 5) // [5 1 2 4 _] -> [0 5 1 2 4]
+	assert.Equal(t, evicted, 3)
+	assert.True(t, overwritten)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 5, 1, 2, 4})
+
+	evicted, _, _ = cb.InsertAt(4, 6) // [5 1 2 6 4]
+	assert.Equal(t, evicted, 0)
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 1, 2, 6, 4})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(2))
+	assert.Nil(t, cb.CheckInvariants())
+
+	_, _, e = cb.InsertAt(6, 7)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+	_, _, e = cb.InsertAt(-1, 7)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+
+	cb = NewCircularBuffer(1, WithOverwritePolicy(Discard))
+	cb.PushBack(0) // [0]
+	evicted, overwritten, _ = cb.InsertAt(0, 1)
+	assert.Equal(t, evicted, 1)
+	assert.True(t, overwritten)
+	assert.Equal(t, cb.ToArray(), []interface{}{0})
+}
+
+func TestCircularBufferInsertSorted(t *testing.T) {
+	cb := NewCircularBuffer(3)
+	byNumber := func(a, b interface{}) int { return a.(int) - b.(int) }
+
+	cb.InsertSorted(5, byNumber, AtFront) // [5 _ _]
+	cb.InsertSorted(1, byNumber, AtFront) // [5 _ 1]
+	evicted, overwritten := cb.InsertSorted(3, byNumber, AtFront)
+	assert.Nil(t, evicted)
+	assert.False(t, overwritten)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 3, 5})
+
+	evicted, overwritten = cb.InsertSorted(4, byNumber, AtFront)
+	assert.Equal(t, evicted, 1)
+	assert.True(t, overwritten)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 4, 5})
+
+	evicted, _ = cb.InsertSorted(2, byNumber, AtFront)
+	assert.Equal(t, evicted, 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 4, 5})
+
+	evicted, _ = cb.InsertSorted(4, byNumber, AtBack)
+	assert.Equal(t, evicted, 5)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 4, 4})
+
+	evicted, _ = cb.InsertSorted(6, byNumber, AtBack)
+	assert.Equal(t, evicted, 6)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 4, 4})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(4))
+	assert.Nil(t, cb.CheckInvariants())
+
+	d := NewDeque(1)
+	d.InsertSorted(2, byNumber, AtFront)
+	evicted, overwritten = d.InsertSorted(1, byNumber, AtFront)
+	assert.Nil(t, evicted)
+	assert.False(t, overwritten)
+	assert.Equal(t, d.ToArray(), []interface{}{1, 2})
+}
+
+func TestCircularBufferIsSortedFunc(t *testing.T) {
+	cb := NewCircularBuffer(3)
+	byLength := func(a, b interface{}) int { return len(a.(string)) - len(b.(string)) }
+	assert.True(t, cb.IsSortedFunc(byLength))
+
+	cb.PushBack("bb")  // [bb _ _]
+	cb.PushBack("ccc") // [bb ccc _]
+	cb.PushFront("a")  // [bb ccc a]
+	assert.True(t, cb.IsSortedFunc(byLength))
+
+	cb.PushBack("d") // [d ccc a]
+	assert.False(t, cb.IsSortedFunc(byLength))
+}
+
+func TestCircularBufferKeep(t *testing.T) {
+	cb := NewCircularBuffer(5)
+
+	cb.PushBack(0)  // [0 _ _ _ _]
+	cb.PushBack(1)  // [0 1 _ _ _]
+	cb.PushBack(2)  // [0 1 2 _ _]
+	cb.PushFront(3) // [0 1 2 _ 3]
+	cb.PushFront(4) // [0 1 2 4 3]
+
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	assert.Equal(t, cb.Keep(odd), 3) // [_ _ _ 3 1]
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 1})
+	assert.Equal(t, cb.buffer, []interface{}{nil, nil, nil, 3, 1})
+
+	assert.Equal(t, cb.Keep(odd), 0)
+	assert.Equal(t, cb.Keep(func(interface{}) bool { return false }), 2)
+	assert.True(t, cb.Empty())
+}
+
+func TestCircularBufferLastIndexFunc(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	assert.Equal(t, cb.LastIndexFunc(odd), -1)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(2)  // [0 2 _ _]
+	cb.PushFront(1) // [0 2 _ 1]
+	cb.PushFront(3) // [0 2 3 1]
+
+	assert.Equal(t, cb.LastIndexFunc(odd), 1)
+	cb.PopFront()  // [0 2 _ 1]
+	cb.PushBack(5) // [0 2 5 1]
+	assert.Equal(t, cb.LastIndexFunc(odd), 3)
+	assert.Equal(t, cb.LastIndexFunc(func(v interface{}) bool { return v.(int) > 5 }), -1)
+}
+
+func TestCircularBufferMap(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(2)  // [1 2 _ _]
+	cb.PushFront(3) // [1 2 _ 3]
+
+	m := cb.Map(func(v interface{}) interface{} { return strconv.Itoa(v.(int) * 10) })
+	assert.Equal(t, m.ToArray(), []interface{}{"30", "10", "20"})
+	assert.Equal(t, m.Capacity(), 4)
+	assert.Equal(t, m.Stats().MaxSize, 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 1, 2})
+	assert.Nil(t, m.CheckInvariants())
+}
+
+func TestCircularBufferMarshalBinary(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	b, e := cb.MarshalBinary()
+	assert.Nil(t, e)
+	assert.Equal(t, b, []byte{'G', 'O', 'C', 'B', 2, 4, 0, 0, 0})
+
+	cb.PushBack("a") // [a _ _ _]
+	cb.PushBack("b") // [a b _ _]
+	cb.SetCodec(StringCodec{})
+
+	b, e = cb.MarshalBinary()
+	assert.Nil(t, e)
+	assert.Equal(t, b, []byte{'G', 'O', 'C', 'B', 2, 4, 2, 0, 0, 1, 'a', 1, 'b'})
+
+	cb.PushBack(0)
+	_, e = cb.MarshalBinary()
+	assert.NotNil(t, e)
+}
+
+func TestCircularBufferMarshalJSON(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	b, e := json.Marshal(cb)
+	assert.Nil(t, e)
+	assert.Equal(t, string(b), "[]")
+
+	cb.PushBack(0)   // [0 _ _ _]
+	cb.PushBack("1") // [0 "1" _ _]
+	cb.PushBack(2)   // [0 "1" 2 _]
+	cb.PushBack(3)   // [0 "1" 2 3]
+	cb.PushBack(4)   // ["1" 2 3 4]
+
+	b, e = json.Marshal(&cb)
+	assert.Nil(t, e)
+	assert.Equal(t, string(b), `["1",2,3,4]`)
+}
+
+func TestCircularBufferMaxFunc(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	byLength := func(a, b interface{}) int { return len(a.(string)) - len(b.(string)) }
+	_, index, ok := cb.MaxFunc(byLength)
+	assert.Equal(t, index, -1)
+	assert.False(t, ok)
+
+	cb.PushBack("a")   // [a _ _ _]
+	cb.PushBack("ccc") // [a ccc _ _]
+	cb.PushBack("ddd") // [a ccc ddd _]
+	cb.PushFront("bb") // [a ccc ddd bb]
+
+	value, index, ok := cb.MaxFunc(byLength)
+	assert.Equal(t, value, "ccc")
+	assert.Equal(t, index, 2)
+	assert.True(t, ok)
+}
+
+func TestCircularBufferMinFunc(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	byLength := func(a, b interface{}) int { return len(a.(string)) - len(b.(string)) }
+	_, index, ok := cb.MinFunc(byLength)
+	assert.Equal(t, index, -1)
+	assert.False(t, ok)
+
+	cb.PushBack("ccc") // [ccc _ _ _]
+	cb.PushBack("a")   // [ccc a _ _]
+	cb.PushFront("bb") // [ccc a _ bb]
+	cb.PushFront("d")  // [ccc a d bb]
+
+	value, index, ok := cb.MinFunc(byLength)
+	assert.Equal(t, value, "d")
+	assert.Equal(t, index, 0)
+	assert.True(t, ok)
+}
+
+func TestCircularBufferMustAt(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2)
+	assert.Equal(t, cb.MustAt(1), 1)
+	assert.PanicsWithError(t, "gocontainers: MustAt(3) on CircularBuffer of size 3: index out of bounds", func() {
+		cb.MustAt(3)
+	})
+}
+
+func TestCircularBufferMustBack(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2)
+	assert.Equal(t, cb.MustBack(), 2)
+
+	cb.Clear()
+	assert.PanicsWithError(t, "gocontainers: MustBack on CircularBuffer: empty buffer", func() {
+		cb.MustBack()
+	})
+}
+
+func TestCircularBufferMustFront(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2)
+	assert.Equal(t, cb.MustFront(), 0)
+
+	cb.Clear()
+	assert.PanicsWithError(t, "gocontainers: MustFront on CircularBuffer: empty buffer", func() {
+		cb.MustFront()
+	})
+}
+
+func TestCircularBufferMustPopFront(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1)
+	assert.Equal(t, cb.MustPopFront(), 0)
+	assert.Equal(t, cb.MustPopFront(), 1)
+	assert.True(t, cb.Empty())
+
+	defer func() {
+		assert.ErrorIs(t, recover().(error), ErrEmpty)
+	}()
+	cb.MustPopFront()
+}
+
+func TestCircularBufferNextSeq(t *testing.T) {
+	cb := NewCircularBuffer(2)
+	assert.Zero(t, cb.NextSeq())
+
+	cb.PushBack(0) // [0 _]
+	cb.PushBack(1) // [0 1]
+	cb.PushBack(2) // [1 2]
+	assert.Equal(t, cb.NextSeq(), uint64(3))
+
+	cb.PopFront() // [2 _]
+	cb.Clear()    // [_ _]
+	assert.Equal(t, cb.NextSeq(), uint64(3))
+}
+
+func TestCircularBufferOccupancyHistogram(t *testing.T) {
+	cb := NewCircularBuffer(2)
+	assert.Nil(t, cb.OccupancyHistogram())
+
+	cb.PushBack(0) // [0 _]
+	cb.EnableOccupancyHistogram()
+	assert.Equal(t, cb.OccupancyHistogram(), []uint64{0, 0, 0})
+
+	cb.PushBack(1) // [0 1]
+	cb.PushBack(2) // [1 2]
+	cb.PopFront()  // [2 _]
+	cb.Clear()     // [_ _]
+	assert.Equal(t, cb.OccupancyHistogram(), []uint64{1, 1, 2})
+
+	cb.Resize(3)   // [_ _ _]
+	cb.PushBack(3) // [3 _ _]
+	cb.PushBack(4) // [3 4 _]
+	cb.PushBack(5) // [3 4 5]
+	assert.Equal(t, cb.OccupancyHistogram(), []uint64{2, 2, 3, 1})
+}
+
+func TestCircularBufferPartition(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(2)  // [1 2 _ _]
+	cb.PushBack(4)  // [1 2 4 _]
+	cb.PushFront(3) // [1 2 4 3]
+
+	match, rest := cb.Partition(func(v interface{}) bool { return v.(int)%2 == 1 })
+	assert.Equal(t, match.ToArray(), []interface{}{3, 1})
+	assert.Equal(t, rest.ToArray(), []interface{}{2, 4})
+	assert.Equal(t, match.Capacity(), 4)
+	assert.Equal(t, rest.Capacity(), 4)
+	assert.Equal(t, cb.Size(), 4)
+	assert.Nil(t, match.CheckInvariants())
+	assert.Nil(t, rest.CheckInvariants())
+}
+
+func TestCircularBufferPopBack(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
 	cb.PushBack(3) // [0 1 2 3]
 	cb.PushBack(4) // [1 2 3 4]
 	cb.PushBack(5) // [2 3 4 5]
@@ -200,6 +1220,22 @@ func TestCircularBufferPopBack(t *testing.T) {
 	assert.Equal(t, a, []interface{}{2, 3})
 }
 
+func TestCircularBufferPopBackWhile(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(3)  // [0 3 _ _]
+	cb.PushFront(4) // [0 3 _ 4]
+	cb.PushBack(5)  // [0 3 5 4]
+
+	greater := func(v interface{}) bool { return v.(int) > 2 }
+	assert.Equal(t, cb.PopBackWhile(greater), 2) // [0 _ _ 4]
+	assert.Equal(t, cb.ToArray(), []interface{}{4, 0})
+	assert.Equal(t, cb.PopBackWhile(greater), 0)
+	assert.Equal(t, cb.PopBackWhile(func(interface{}) bool { return true }), 2)
+	assert.True(t, cb.Empty())
+}
+
 func TestCircularBufferPopFront(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
@@ -217,6 +1253,52 @@ func TestCircularBufferPopFront(t *testing.T) {
 	assert.Equal(t, a, []interface{}{4, 5})
 }
 
+func TestCircularBufferPopFrontInto(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	cb.PushBack(5) // [4 5 2 3]
+
+	dst := make([]interface{}, 3)
+	n := cb.PopFrontInto(dst) // [_ 5 _ _]
+	assert.Equal(t, n, 3)
+	assert.Equal(t, dst, []interface{}{2, 3, 4})
+	assert.Equal(t, cb.ToArray(), []interface{}{5})
+	assert.Equal(t, cb.NextSeq(), uint64(6))
+	assert.Equal(t, cb.Stats().PopFronts, uint64(3))
+	assert.Nil(t, cb.CheckInvariants())
+
+	n = cb.PopFrontInto(dst) // [_ _ _ _]
+	assert.Equal(t, n, 1)
+	assert.Equal(t, dst[0], 5)
+	assert.True(t, cb.Empty())
+	assert.Nil(t, cb.CheckInvariants())
+
+	n = cb.PopFrontInto(dst)
+	assert.Equal(t, n, 0)
+}
+
+func TestCircularBufferPopFrontWhile(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(3)  // [0 3 _ _]
+	cb.PushFront(1) // [0 3 _ 1]
+	cb.PushBack(5)  // [0 3 5 1]
+
+	less := func(v interface{}) bool { return v.(int) < 2 }
+	assert.Equal(t, cb.PopFrontWhile(less), 2) // [_ 3 5 _]
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 5})
+	assert.Equal(t, cb.NextSeq(), uint64(3))
+	assert.Equal(t, cb.PopFrontWhile(less), 0)
+	assert.Equal(t, cb.PopFrontWhile(func(interface{}) bool { return true }), 2)
+	assert.True(t, cb.Empty())
+}
+
 func TestCircularBufferPushBack(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
@@ -228,6 +1310,42 @@ func TestCircularBufferPushBack(t *testing.T) {
 	cb.PushBack(5) // [2 3 4 5]
 
 	assert.Equal(t, cb.ToArray(), []interface{}{2, 3, 4, 5})
+
+	var evicted []interface{}
+	cb = NewCircularBuffer(0, WithEvictionCallback(func(v interface{}) { evicted = append(evicted, v) }))
+	cb.PushBack(0)
+	assert.True(t, cb.Empty())
+	assert.Equal(t, evicted, []interface{}{0})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(1))
+}
+
+func TestCircularBufferPushBackSlice(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	n := cb.PushBackSlice([]interface{}{0, 1, 2}) // [0 1 2 _]
+	assert.Equal(t, n, 0)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1, 2})
+
+	n = cb.PushBackSlice([]interface{}{3, 4}) // [4 1 2 3]
+	assert.Equal(t, n, 1)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3, 4})
+	assert.Nil(t, cb.CheckInvariants())
+
+	n = cb.PushBackSlice([]interface{}{5, 6, 7, 8, 9, 10}) // [7 8 9 10]
+	assert.Equal(t, n, 6)
+	assert.Equal(t, cb.ToArray(), []interface{}{7, 8, 9, 10})
+	assert.Nil(t, cb.CheckInvariants())
+
+	loop := NewCircularBuffer(4)
+	for i := 0; i <= 10; i++ {
+		loop.PushBack(i)
+	}
+	assert.Equal(t, cb.Stats(), loop.Stats())
+	assert.Equal(t, cb.NextSeq(), loop.NextSeq())
+
+	n = cb.PushBackSlice(nil)
+	assert.Equal(t, n, 0)
+	assert.Equal(t, cb.ToArray(), []interface{}{7, 8, 9, 10})
 }
 
 func TestCircularBufferPushFront(t *testing.T) {
@@ -241,6 +1359,381 @@ func TestCircularBufferPushFront(t *testing.T) {
 	cb.PushFront(5) // [5 4 3 2]
 
 	assert.Equal(t, cb.ToArray(), []interface{}{5, 4, 3, 2})
+
+	var evicted []interface{}
+	cb = NewCircularBuffer(0, WithEvictionCallback(func(v interface{}) { evicted = append(evicted, v) }))
+	cb.PushFront(0)
+	assert.True(t, cb.Empty())
+	assert.Equal(t, evicted, []interface{}{0})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(1))
+}
+
+func TestCircularBufferPushFrontSlice(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	n := cb.PushFrontSlice([]interface{}{1, 2}) // [_ _ 1 2]
+	assert.Equal(t, n, 0)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2})
+
+	n = cb.PushFrontSlice([]interface{}{-2, -1, 0}) // [-1 0 -2 1]
+	assert.Equal(t, n, 1)
+	assert.Equal(t, cb.ToArray(), []interface{}{-2, -1, 0, 1})
+	assert.Nil(t, cb.CheckInvariants())
+
+	n = cb.PushFrontSlice([]interface{}{-9, -8, -7, -6, -5}) // [-9 -8 -7 -6]
+	assert.Equal(t, n, 5)
+	assert.Equal(t, cb.ToArray(), []interface{}{-9, -8, -7, -6})
+	assert.Nil(t, cb.CheckInvariants())
+
+	loop := NewCircularBuffer(4)
+	for _, v := range []interface{}{2, 1, 0, -1, -2, -5, -6, -7, -8, -9} {
+		loop.PushFront(v)
+	}
+	assert.Equal(t, cb.Stats(), loop.Stats())
+	assert.Equal(t, cb.NextSeq(), loop.NextSeq())
+}
+
+func TestCircularBufferRaw(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	cb.PopBack()   // [_ 1 2 3]
+
+	buf, head, length := cb.Raw()
+	assert.Equal(t, buf, []interface{}{nil, 1, 2, 3})
+	assert.Equal(t, head, 1)
+	assert.Equal(t, length, 3)
+
+	buf[(head+2)%len(buf)] = 5 // [_ 1 2 5]
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 5})
+
+	cb.Resize(2) // [1 2]
+	buf, head, length = cb.Raw()
+	assert.Equal(t, buf, []interface{}{1, 2})
+	assert.Equal(t, head, 0)
+	assert.Equal(t, length, 2)
+}
+
+func TestCircularBufferReadCSV(t *testing.T) {
+	cb := NewCircularBuffer(2)
+
+	e := cb.ReadCSV(strings.NewReader("a,b\n0,1\n2,3\n4,5\n"), true, nil)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{[]string{"2", "3"}, []string{"4", "5"}})
+
+	parse := func(record []string) (interface{}, error) {
+		return strconv.Atoi(record[0])
+	}
+	e = cb.ReadCSV(strings.NewReader("6\n7\n8\n"), false, parse)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{7, 8})
+
+	e = cb.ReadCSV(strings.NewReader("x\n"), false, parse)
+	assert.NotNil(t, e)
+
+	e = cb.ReadCSV(strings.NewReader("\"x\n"), false, nil)
+	assert.NotNil(t, e)
+}
+
+func TestCircularBufferReadSnapshot(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.SetCodec(StringCodec{})
+
+	e := cb.ReadSnapshot(bytes.NewReader([]byte{'G', 'O', 'C', 'B', 2, 3, 2, 2, 7, 1, 'a', 1, 'b'}))
+	assert.Nil(t, e)
+	assert.Nil(t, cb.CheckInvariants())
+	assert.Equal(t, cb.Capacity(), 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{"a", "b"})
+	assert.Equal(t, cb.buffer, []interface{}{"b", nil, "a"})
+	assert.Equal(t, cb.NextSeq(), uint64(9))
+
+	e = cb.ReadSnapshot(bytes.NewReader([]byte{1, 2, 1, 1, 'a'}))
+	assert.Nil(t, e)
+	assert.Equal(t, cb.Capacity(), 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{"a"})
+
+	e = cb.ReadSnapshot(bytes.NewReader([]byte{'G', 'O', 'C', 'B', 3}))
+	assert.NotNil(t, e)
+	e = cb.ReadSnapshot(bytes.NewReader([]byte{'G', 'O', 'C', 'B', 2, 2, 0, 2, 0}))
+	assert.NotNil(t, e)
+	e = cb.ReadSnapshot(bytes.NewReader([]byte{'G', 'O', 'C', 'B', 2, 2, 0}))
+	assert.NotNil(t, e)
+	e = cb.ReadSnapshot(bytes.NewReader([]byte{0}))
+	assert.NotNil(t, e)
+
+	hostile := append([]byte{'G', 'O', 'C', 'B', 2}, binary.AppendUvarint(nil, 1<<40)...)
+	e = cb.ReadSnapshot(bytes.NewReader(append(hostile, 0, 0, 0)))
+	assert.NotNil(t, e)
+	hostile = append([]byte{'G', 'O', 'C', 'B', 2}, binary.AppendUvarint(nil, 1<<63)...)
+	e = cb.ReadSnapshot(bytes.NewReader(append(hostile, 0, 0, 0)))
+	assert.NotNil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{"a"})
+}
+
+func TestCircularBufferReadSince(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	v, next := cb.ReadSince(0)
+	assert.Empty(t, v)
+	assert.Zero(t, next)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+
+	v, next = cb.ReadSince(next)
+	assert.Equal(t, v, []interface{}{0, 1})
+	assert.Equal(t, next, uint64(2))
+
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [1 2 3 4]
+	cb.PushBack(5) // [2 3 4 5]
+
+	v, next = cb.ReadSince(next)
+	assert.Equal(t, v, []interface{}{2, 3, 4, 5})
+	assert.Equal(t, next, uint64(6))
+
+	cb.PushBack(6) // [3 4 5 6]
+	cb.PushBack(7) // [4 5 6 7]
+	cb.PushBack(8) // [5 6 7 8]
+
+	v, next = cb.ReadSince(next)
+	assert.Equal(t, v, []interface{}{6, 7, 8})
+	assert.Equal(t, next, uint64(9))
+
+	cb.PushBack(9)  // [6 7 8 9]
+	cb.PushBack(10) // [7 8 9 10]
+	cb.PushBack(11) // [8 9 10 11]
+	cb.PushBack(12) // [9 10 11 12]
+	cb.PushBack(13) // [10 11 12 13]
+
+	v, next = cb.ReadSince(next)
+	assert.Equal(t, v, []interface{}{10, 11, 12, 13})
+	assert.Equal(t, next, uint64(14))
+
+	v, next = cb.ReadSince(100)
+	assert.Empty(t, v)
+	assert.Equal(t, next, uint64(14))
+}
+
+func TestCircularBufferRemoveAt(t *testing.T) {
+	cb := NewCircularBuffer(5)
+
+	cb.PushBack(0)  // [0 _ _ _ _]
+	cb.PushBack(1)  // [0 1 _ _ _]
+	cb.PushBack(2)  // [0 1 2 _ _]
+	cb.PushBack(3)  // [0 1 2 3 _]
+	cb.PushFront(4) // [0 1 2 3 4]
+
+	value, e := cb.RemoveAt(1) // [_ 1 2 3 4]
+	assert.Equal(t, value, 0)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{4, 1, 2, 3})
+	assert.Equal(t, cb.buffer, []interface{}{4, 1, 2, 3, nil})
+
+	value, e = cb.RemoveAt(-2) // [_ 1 3 _ 4]
+	assert.Equal(t, value, 2)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{4, 1, 3})
+	assert.Equal(t, cb.buffer, []interface{}{4, 1, 3, nil, nil})
+	assert.Nil(t, cb.CheckInvariants())
+
+	value, _ = cb.RemoveAt(0) // [_ 1 3 _ _]
+	assert.Equal(t, value, 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 3})
+	assert.Equal(t, cb.NextSeq(), uint64(4))
+
+	_, e = cb.RemoveAt(2)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+	_, e = cb.RemoveAt(-3)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+}
+
+func TestCircularBufferRemoveFunc(t *testing.T) {
+	cb := NewCircularBuffer(5)
+
+	cb.PushBack(0)  // [0 _ _ _ _]
+	cb.PushBack(1)  // [0 1 _ _ _]
+	cb.PushBack(2)  // [0 1 2 _ _]
+	cb.PushFront(3) // [0 1 2 _ 3]
+	cb.PushFront(4) // [0 1 2 4 3]
+
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	assert.Equal(t, cb.RemoveFunc(odd), 2) // [2 _ _ 4 0]
+	assert.Equal(t, cb.ToArray(), []interface{}{4, 0, 2})
+	assert.Equal(t, cb.buffer, []interface{}{2, nil, nil, 4, 0})
+	assert.Equal(t, cb.NextSeq(), uint64(3))
+	assert.Nil(t, cb.CheckInvariants())
+
+	assert.Equal(t, cb.RemoveFunc(odd), 0)
+	assert.Equal(t, cb.RemoveFunc(func(interface{}) bool { return true }), 3)
+	assert.True(t, cb.Empty())
+	assert.Equal(t, cb.buffer, make([]interface{}, 5))
+}
+
+func TestCircularBufferReplace(t *testing.T) {
+	var evicted []interface{}
+	cb := NewCircularBuffer(5, WithEvictionCallback(func(v interface{}) { evicted = append(evicted, v) }))
+
+	cb.PushBack(0) // [0 _ _ _ _]
+	cb.PushBack(1) // [0 1 _ _ _]
+	cb.PushBack(2) // [0 1 2 _ _]
+
+	assert.Nil(t, cb.Replace(1, 2, 3, 4)) // [0 3 4 2 _]
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 3, 4, 2})
+	assert.Equal(t, cb.NextSeq(), uint64(4))
+
+	assert.Nil(t, cb.Replace(1, 3, 5)) // [0 5 2 _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 5, 2})
+	assert.Equal(t, cb.buffer, []interface{}{0, 5, 2, nil, nil})
+
+	assert.Nil(t, cb.Replace(2, 2, 6, 7, 8, 9)) // [9 2 6 7 8]
+	assert.Equal(t, cb.ToArray(), []interface{}{6, 7, 8, 9, 2})
+	assert.Equal(t, cb.buffer, []interface{}{9, 2, 6, 7, 8})
+	assert.Equal(t, evicted, []interface{}{0, 5})
+	assert.Equal(t, cb.NextSeq(), uint64(8))
+	assert.Nil(t, cb.CheckInvariants())
+
+	evicted = nil
+	assert.Nil(t, cb.Replace(0, 0, 10, 11))
+	assert.Equal(t, cb.ToArray(), []interface{}{6, 7, 8, 9, 2})
+	assert.Equal(t, evicted, []interface{}{10, 11})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(4))
+	assert.Equal(t, cb.NextSeq(), uint64(10))
+
+	assert.ErrorIs(t, cb.Replace(4, 6), ErrIndexOutOfRange)
+	assert.ErrorIs(t, cb.Replace(2, 1), ErrIndexOutOfRange)
+
+	cb = NewCircularBuffer(2, WithOverwritePolicy(Discard))
+	cb.PushBack(0) // [0 _]
+	assert.Nil(t, cb.Replace(0, 0, 1, 2))
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 0})
+
+	evicted = nil
+	cb = NewCircularBuffer(7, WithEvictionCallback(func(v interface{}) { evicted = append(evicted, v) }))
+	cb.PushBack(0) // [0 _ _ _ _ _ _]
+	cb.PushBack(1) // [0 1 _ _ _ _ _]
+	vs := make([]interface{}, 20)
+	for k := range vs {
+		vs[k] = k + 10
+	}
+	assert.Nil(t, cb.Replace(1, 1, vs...))
+	assert.Equal(t, cb.ToArray(), []interface{}{24, 25, 26, 27, 28, 29, 1})
+	assert.Equal(t, evicted, append([]interface{}{0}, vs[:14]...))
+	assert.Equal(t, cb.Stats().Overwrites, uint64(15))
+	assert.Equal(t, cb.NextSeq(), uint64(22))
+	assert.Nil(t, cb.CheckInvariants())
+
+	assert.Nil(t, cb.Replace(0, 0, vs...))
+	assert.Equal(t, cb.ToArray(), []interface{}{24, 25, 26, 27, 28, 29, 1})
+	assert.Nil(t, cb.CheckInvariants())
+}
+
+func TestCircularBufferReset(t *testing.T) {
+	cb := NewCircularBuffer(4, WithOccupancyHistogram())
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	buffer := cb.buffer
+
+	cb.Reset() // [_ _ _ _]
+	assert.True(t, cb.Empty())
+	assert.Equal(t, cb.buffer, []interface{}{nil, nil, nil, nil})
+	assert.Equal(t, &cb.buffer[0], &buffer[0])
+	assert.Equal(t, cb.NextSeq(), uint64(5))
+	assert.Equal(t, cb.Stats(), Stats{})
+	assert.Equal(t, cb.OccupancyHistogram(), []uint64{0, 0, 0, 0, 0})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.PushBack(5) // [5 _ _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{5})
+
+	cb = NewCircularBuffer(4)
+	c := NewCursor(&cb)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	c.Ack(2)
+	cb.Reset()     // [_ _ _ _]
+	cb.PushBack(3) // [3 _ _ _]
+	v, missed := c.Read(0)
+	assert.Equal(t, v, []interface{}{3})
+	assert.Zero(t, missed)
+}
+
+func TestCircularBufferRestore(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+
+	data, e := cb.Snapshot()
+	assert.Nil(t, e)
+
+	var rcb CircularBuffer
+	e = rcb.Restore(data)
+	assert.Nil(t, e)
+	assert.Equal(t, rcb.Capacity(), 4)
+	assert.Equal(t, rcb.ToArray(), []interface{}{0, 1, 2})
+
+	e = rcb.Restore(data[:3])
+	assert.NotNil(t, e)
+
+	rcb = NewCircularBuffer(2, WithOccupancyHistogram(), WithLazyAllocation(), WithPowerOfTwoCapacity(),
+		WithGrowUpTo(16), WithAutoShrink(0.25, 2))
+	rcb.PushBack(0) // [0 _]
+	assert.Equal(t, rcb.OccupancyHistogram(), []uint64{0, 1, 0})
+	cb = NewCircularBufferWithValues(3, 0, 1)
+	data, _ = cb.Snapshot()
+	assert.Nil(t, rcb.Restore(data))
+	assert.Equal(t, rcb.Capacity(), 4)
+	assert.Equal(t, rcb.ToArray(), []interface{}{0, 1})
+	assert.Equal(t, rcb.OccupancyHistogram(), []uint64{0, 0, 0, 0, 0})
+	assert.True(t, rcb.lazy)
+	assert.Equal(t, rcb.shrink.floor, 2)
+	assert.Nil(t, rcb.CheckInvariants())
+
+	assert.Nil(t, rcb.RestoreWithCapacity(data, 1))
+	assert.Equal(t, rcb.ToArray(), []interface{}{1})
+	assert.NotNil(t, rcb.OccupancyHistogram())
+
+	assert.Nil(t, json.Unmarshal([]byte(`[0]`), &rcb))
+	assert.NotNil(t, rcb.OccupancyHistogram())
+	assert.Equal(t, rcb.shrink.floor, 2)
+}
+
+func TestCircularBufferRestoreWithCapacity(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [1 2 3 4]
+
+	data, _ := cb.Snapshot()
+
+	var rcb CircularBuffer
+	e := rcb.RestoreWithCapacity(data, 6)
+	assert.Nil(t, e)
+	assert.Nil(t, rcb.CheckInvariants())
+	assert.Equal(t, rcb.Capacity(), 6)
+	assert.Equal(t, rcb.ToArray(), []interface{}{1, 2, 3, 4})
+	assert.Equal(t, rcb.NextSeq(), uint64(5))
+
+	e = rcb.RestoreWithCapacity(data, 2)
+	assert.Nil(t, e)
+	assert.Nil(t, rcb.CheckInvariants())
+	assert.Equal(t, rcb.ToArray(), []interface{}{3, 4})
+	assert.Equal(t, rcb.NextSeq(), uint64(5))
+
+	e = rcb.RestoreWithCapacity(data[:3], 2)
+	assert.NotNil(t, e)
 }
 
 func TestCircularBufferResize(t *testing.T) {
@@ -263,11 +1756,90 @@ func TestCircularBufferResize(t *testing.T) {
 
 	cb.Resize(2) // [4 5]
 	assert.Equal(t, cb.ToArray(), []interface{}{4, 5})
+	assert.Equal(t, cb.buffer, []interface{}{4, 5, nil, nil, nil, nil})
 
 	cb.PushBack(10)
 	assert.Equal(t, cb.ToArray(), []interface{}{5, 10})
 }
 
+func TestCircularBufferResizeKeepBack(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+
+	cb.ResizeKeepBack(2) // [3 4]
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 4})
+	assert.Equal(t, cb.NextSeq(), uint64(5))
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.ResizeKeepBack(3) // [3 4 _]
+	cb.PushBack(5)       // [3 4 5]
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 4, 5})
+}
+
+func TestCircularBufferSample(t *testing.T) {
+	cb := NewCircularBuffer(8)
+	r := rand.New(rand.NewPCG(1, 2))
+	assert.Equal(t, cb.Sample(3, r), []interface{}{})
+
+	for i := 0; i < 6; i++ {
+		cb.PushBack(i)
+	}
+	cb.PushFront(-1) // [0 1 2 3 4 5 _ -1]
+
+	for i := 0; i < 100; i++ {
+		sample := cb.Sample(3, r)
+		assert.Len(t, sample, 3)
+		assert.True(t, slices.IsSortedFunc(sample, func(a, b interface{}) int { return a.(int) - b.(int) }))
+		assert.Len(t, slices.Compact(slices.Clone(sample)), 3)
+	}
+	assert.Equal(t, cb.Sample(10, r), []interface{}{-1, 0, 1, 2, 3, 4, 5})
+	assert.Equal(t, cb.Sample(-1, r), []interface{}{})
+
+	counts := make(map[interface{}]int)
+	for i := 0; i < 7000; i++ {
+		for _, v := range cb.Sample(1, r) {
+			counts[v]++
+		}
+	}
+	for _, count := range counts {
+		assert.InDelta(t, count, 1000, 150)
+	}
+	assert.Len(t, counts, 7)
+}
+
+func TestCircularBufferSet(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	e := cb.Set(0, 0)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [1 2 3 4]
+
+	e = cb.Set(0, 5) // [5 2 3 4]
+	assert.Nil(t, e)
+	e = cb.Set(3, 6) // [5 2 3 6]
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 2, 3, 6})
+
+	e = cb.Set(-1, 7) // [5 2 3 7]
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 2, 3, 7})
+
+	e = cb.Set(-5, 7)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+	e = cb.Set(4, 7)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+}
+
 func TestCircularBufferShift(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
@@ -281,6 +1853,68 @@ func TestCircularBufferShift(t *testing.T) {
 
 	cb.shiftToZero() // [2 3 4 5]
 	assert.Equal(t, cb.buffer, []interface{}{2, 3, 4, 5})
+
+	cb.PushBack(6)   // [6 3 4 5]
+	cb.PushBack(7)   // [6 7 4 5]
+	cb.PushBack(8)   // [6 7 8 5]
+	cb.PopBack()     // [6 7 _ 5]
+	cb.shiftToZero() // [5 6 7 _]
+	assert.Equal(t, cb.buffer, []interface{}{5, 6, 7, nil})
+
+	cb.PopFront()    // [_ 6 7 _]
+	cb.shiftToZero() // [6 7 _ _]
+	assert.Equal(t, cb.buffer, []interface{}{6, 7, nil, nil})
+}
+
+func TestCircularBufferShrinkToFit(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	cb.PopFront()  // [4 _ 2 3]
+
+	cb.ShrinkToFit() // [2 3 4]
+	assert.Equal(t, cb.Capacity(), 3)
+	assert.Equal(t, len(cb.buffer), 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{2, 3, 4})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.Clear()
+	cb.ShrinkToFit() // [_]
+	assert.Equal(t, cb.Capacity(), 1)
+	cb.PushBack(5) // [5]
+	assert.Equal(t, cb.ToArray(), []interface{}{5})
+}
+
+func TestCircularBufferSnapshot(t *testing.T) {
+	cb := NewCircularBuffer(2)
+	cb.PushBack("a")
+	cb.SetCodec(StringCodec{})
+
+	data, e := cb.Snapshot()
+	assert.Nil(t, e)
+	assert.Equal(t, data, []byte{'G', 'O', 'C', 'B', 2, 2, 1, 0, 0, 1, 'a'})
+}
+
+func TestCircularBufferShuffle(t *testing.T) {
+	cb := NewCircularBuffer(8)
+	cb.Shuffle(rand.New(rand.NewPCG(1, 2)))
+	assert.True(t, cb.Empty())
+
+	for i := 0; i < 6; i++ {
+		cb.PushBack(i)
+	}
+	cb.PushFront(6) // [0 1 2 3 4 5 _ 6]
+	expected := cb.ToArray()
+
+	cb.Shuffle(rand.New(rand.NewPCG(1, 2)))
+	rand.New(rand.NewPCG(1, 2)).Shuffle(len(expected), func(i, j int) { expected[i], expected[j] = expected[j], expected[i] })
+	assert.Equal(t, cb.ToArray(), expected)
+	assert.Equal(t, cb.Size(), 7)
+	assert.Nil(t, cb.buffer[6])
 }
 
 func TestCircularBufferSize(t *testing.T) {
@@ -298,6 +1932,214 @@ func TestCircularBufferSize(t *testing.T) {
 	assert.Equal(t, cb.Size(), 4)
 }
 
+func TestCircularBufferSlices(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	first, second := cb.Slices()
+	assert.Empty(t, first)
+	assert.Nil(t, second)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	first, second = cb.Slices()
+	assert.Equal(t, first, []interface{}{0, 1})
+	assert.Nil(t, second)
+	assert.Equal(t, cap(first), 2)
+
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	first, second = cb.Slices()
+	assert.Equal(t, first, []interface{}{1, 2, 3})
+	assert.Equal(t, second, []interface{}{4})
+	assert.Equal(t, cap(second), 1)
+
+	first[0] = 5 // [4 5 2 3]
+	assert.Equal(t, cb.ToArray(), []interface{}{5, 2, 3, 4})
+}
+
+func TestCircularBufferSortFunc(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	byNumber := func(a, b interface{}) int { return a.(int) - b.(int) }
+	cb.SortFunc(byNumber)
+	assert.True(t, cb.Empty())
+
+	cb.PushBack(3)  // [3 _ _ _]
+	cb.PushBack(1)  // [3 1 _ _]
+	cb.PushFront(2) // [3 1 _ 2]
+
+	cb.SortFunc(byNumber) // [1 2 3 _]
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3})
+	assert.Equal(t, cb.buffer, []interface{}{1, 2, 3, nil})
+	assert.Nil(t, cb.CheckInvariants())
+}
+
+func TestCircularBufferSortInterface(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack("bb")  // [bb _ _ _]
+	cb.PushBack("a")   // [bb a _ _]
+	cb.PushFront("c")  // [bb a _ c]
+	cb.PushFront("dd") // [bb a dd c]
+
+	s := cb.SortInterface(func(a, b interface{}) bool { return len(a.(string)) < len(b.(string)) })
+	assert.Equal(t, s.Len(), 4)
+	assert.False(t, s.Less(0, 1))
+	sort.Stable(s) // [dd bb c a]
+	assert.Equal(t, cb.ToArray(), []interface{}{"c", "a", "dd", "bb"})
+	assert.Equal(t, cb.buffer, []interface{}{"dd", "bb", "c", "a"})
+	assert.True(t, sort.IsSorted(s))
+}
+
+func TestCircularBufferSplitAt(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	front, back, e := cb.SplitAt(1)
+	assert.Nil(t, e)
+	assert.Equal(t, front.ToArray(), []interface{}{2})
+	assert.Equal(t, front.Capacity(), 1)
+	assert.Equal(t, back.ToArray(), []interface{}{0, 1})
+	assert.Equal(t, back.Capacity(), 2)
+	assert.Equal(t, cb.Size(), 3)
+
+	front, back, _ = cb.SplitAt(3)
+	assert.Equal(t, front.Size(), 3)
+	assert.True(t, back.Empty())
+
+	_, _, e = cb.SplitAt(4)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+}
+
+func TestCircularBufferStats(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Equal(t, cb.Stats(), Stats{})
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushBack(2)  // [0 1 2 _]
+	cb.PushBack(3)  // [0 1 2 3]
+	cb.PushBack(4)  // [1 2 3 4]
+	cb.PushFront(5) // [5 1 2 3]
+	cb.PopBack()    // [5 1 2 _]
+	cb.PopFront()   // [1 2 _ _]
+	cb.PopFront()   // [2 _ _ _]
+
+	assert.Equal(t, cb.Stats(), Stats{
+		PushBacks:  5,
+		PushFronts: 1,
+		PopBacks:   1,
+		PopFronts:  2,
+		Overwrites: 2,
+		Size:       1,
+		MaxSize:    4,
+	})
+
+	cb.Clear()
+	cb.PopFront()
+	assert.Equal(t, cb.Stats().PopFronts, uint64(2))
+	assert.Zero(t, cb.Stats().Size)
+}
+
+func TestCircularBufferString(t *testing.T) {
+	cb := NewCircularBuffer(5)
+	assert.Equal(t, fmt.Sprint(cb), "CircularBuffer[len=0 cap=5]{}")
+
+	cb.PushFront(3) // [3 _ _ _ _]
+	cb.PushFront(2) // [2 3 _ _ _]
+	cb.PushFront(1) // [1 2 3 _ _]
+	assert.Equal(t, fmt.Sprint(&cb), "CircularBuffer[len=3 cap=5]{1 2 3}")
+
+	cb = NewCircularBuffer(maxFormatElements + 2)
+	for i := 0; i < maxFormatElements+2; i++ {
+		cb.PushBack(i)
+	}
+	assert.True(t, strings.HasSuffix(cb.String(), "30 31 ... 2 more}"))
+}
+
+func TestCircularBufferUnmarshalBinary(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)   // [0 _ _ _]
+	cb.PushBack("1") // [0 "1" _ _]
+	cb.PushBack(2.0) // [0 "1" 2.0 _]
+	cb.PushBack(3)   // [0 "1" 2.0 3]
+	cb.PushBack(4)   // ["1" 2.0 3 4]
+
+	b, e := cb.MarshalBinary()
+	assert.Nil(t, e)
+
+	var rcb CircularBuffer
+	e = rcb.UnmarshalBinary(b)
+	assert.Nil(t, e)
+	assert.Equal(t, rcb.Capacity(), 4)
+	assert.Equal(t, rcb.ToArray(), []interface{}{"1", 2.0, 3, 4})
+
+	rcb.SetCodec(StringCodec{})
+	e = rcb.UnmarshalBinary([]byte{1, 2, 1, 1, 'a'})
+	assert.Nil(t, e)
+	assert.Equal(t, rcb.ToArray(), []interface{}{"a"})
+
+	assert.NotNil(t, rcb.UnmarshalBinary(nil))
+	assert.NotNil(t, rcb.UnmarshalBinary([]byte{2, 0, 0}))
+	assert.NotNil(t, rcb.UnmarshalBinary([]byte{1, 1, 2, 1, 'a', 1, 'b'}))
+	assert.NotNil(t, rcb.UnmarshalBinary([]byte{1, 2, 1, 2, 'a'}))
+	assert.NotNil(t, rcb.UnmarshalBinary([]byte{1, 2, 1, 1, 'a', 'b'}))
+}
+
+func TestCircularBufferUnmarshalJSON(t *testing.T) {
+	var cb CircularBuffer
+	e := json.Unmarshal([]byte(`[0, "1", 2]`), &cb)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.Capacity(), 3)
+	assert.Equal(t, cb.ToArray(), []interface{}{0.0, "1", 2.0})
+
+	cb = NewCircularBuffer(2)
+	e = json.Unmarshal([]byte(`[0, 1, 2]`), &cb)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.Capacity(), 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{1.0, 2.0})
+
+	e = json.Unmarshal([]byte(`{"len":2,"cap":4,"overwrites":0,"items":[0,1]}`), &cb)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.Capacity(), 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{0.0, 1.0})
+
+	e = json.Unmarshal([]byte(`[]`), &CircularBuffer{})
+	assert.Nil(t, e)
+
+	e = json.Unmarshal([]byte(`{"cap":-1}`), &cb)
+	assert.NotNil(t, e)
+	e = json.Unmarshal([]byte(`{"cap":1099511627776,"items":[]}`), &cb)
+	assert.NotNil(t, e)
+	assert.Equal(t, cb.Capacity(), 4)
+
+	e = json.Unmarshal([]byte(` null `), &cb)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{0.0, 1.0})
+
+	e = json.Unmarshal([]byte(`0`), &cb)
+	assert.NotNil(t, e)
+}
+
+func TestCircularBufferTail(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Equal(t, cb.Tail(2), []interface{}{})
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	assert.Equal(t, cb.Tail(1), []interface{}{1})
+	assert.Equal(t, cb.Tail(2), []interface{}{0, 1})
+	assert.Equal(t, cb.Tail(3), []interface{}{2, 0, 1})
+	assert.Equal(t, cb.Tail(5), []interface{}{2, 0, 1})
+	assert.Equal(t, cb.Tail(-1), []interface{}{})
+}
+
 func TestCircularBufferToArray(t *testing.T) {
 	cb := NewCircularBuffer(4)
 
@@ -315,6 +2157,193 @@ func TestCircularBufferToArray(t *testing.T) {
 	assert.Equal(t, a, []interface{}{4, 5, 2, 3})
 }
 
+func TestCircularBufferTruncate(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+	cb.PushFront(3) // [0 1 3 2]
+
+	cb.Truncate(5)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 2, 0, 1})
+
+	cb.Truncate(1) // [_ _ 3 _]
+	assert.Equal(t, cb.ToArray(), []interface{}{3})
+	assert.Equal(t, cb.buffer, []interface{}{nil, nil, 3, nil})
+	assert.Equal(t, cb.Stats().PopBacks, uint64(3))
+
+	cb.Truncate(0)
+	assert.True(t, cb.Empty())
+	assert.PanicsWithValue(t, "gocontainers: CircularBuffer.Truncate: negative count", func() { cb.Truncate(-1) })
+}
+
+func TestCircularBufferTruncateBack(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+	cb.PushFront(3) // [0 1 3 2]
+
+	cb.TruncateBack(4)
+	assert.Equal(t, cb.ToArray(), []interface{}{3, 2, 0, 1})
+
+	cb.TruncateBack(1) // [_ 1 _ _]
+	assert.Equal(t, cb.ToArray(), []interface{}{1})
+	assert.Equal(t, cb.buffer, []interface{}{nil, 1, nil, nil})
+	assert.Equal(t, cb.Stats().PopFronts, uint64(3))
+	assert.Equal(t, cb.NextSeq(), uint64(2))
+
+	cb.TruncateBack(0)
+	assert.True(t, cb.Empty())
+	assert.PanicsWithValue(t, "gocontainers: CircularBuffer.TruncateBack: negative count", func() { cb.TruncateBack(-1) })
+}
+
+func TestCircularBufferTryPushBack(t *testing.T) {
+	cb := NewCircularBuffer(2)
+
+	assert.Nil(t, cb.TryPushBack(0)) // [0 _]
+	assert.Nil(t, cb.TryPushBack(1)) // [0 1]
+
+	e := cb.TryPushBack(2)
+	assert.ErrorIs(t, e, ErrFull)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1})
+	assert.Zero(t, cb.Stats().Overwrites)
+}
+
+func TestCircularBufferTryPushFront(t *testing.T) {
+	cb := NewCircularBuffer(2)
+
+	assert.Nil(t, cb.TryPushFront(1)) // [_ 1]
+	assert.Nil(t, cb.TryPushFront(0)) // [0 1]
+
+	e := cb.TryPushFront(2)
+	assert.ErrorIs(t, e, ErrFull)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1})
+	assert.Zero(t, cb.Stats().Overwrites)
+}
+
+func TestCircularBufferUnique(t *testing.T) {
+	cb := NewCircularBuffer(6)
+
+	cb.PushBack(1)  // [1 _ _ _ _ _]
+	cb.PushBack(2)  // [1 2 _ _ _ _]
+	cb.PushBack(1)  // [1 2 1 _ _ _]
+	cb.PushBack(3)  // [1 2 1 3 _ _]
+	cb.PushFront(3) // [1 2 1 3 _ 3]
+	cb.PushFront(2) // [1 2 1 3 2 3]
+
+	assert.Equal(t, cb.Unique(), 3) // [1 _ _ _ 2 3]
+	assert.Equal(t, cb.ToArray(), []interface{}{2, 3, 1})
+	assert.Equal(t, cb.buffer, []interface{}{1, nil, nil, nil, 2, 3})
+	assert.Equal(t, cb.Unique(), 0)
+
+	cb = NewCircularBuffer(2)
+	assert.Equal(t, cb.Unique(), 0)
+}
+
+func TestCircularBufferUpdateAt(t *testing.T) {
+	type counter struct{ n int }
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(&counter{}) // [c0 _ _ _]
+	cb.PushBack(1)          // [c0 1 _ _]
+
+	e := cb.UpdateAt(0, func(v *interface{}) {
+		(*v).(*counter).n++
+	})
+	assert.Nil(t, e)
+	assert.Equal(t, cb.MustFront().(*counter).n, 1)
+
+	e = cb.UpdateAt(-1, func(v *interface{}) {
+		*v = (*v).(int) + 1
+	}) // [c0 2 _ _]
+	assert.Nil(t, e)
+	assert.Equal(t, cb.MustBack(), 2)
+
+	e = cb.UpdateAt(2, func(v *interface{}) {
+		t.Fail()
+	})
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+}
+
+func TestCircularBufferValues(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Empty(t, slices.Collect(cb.Values()))
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [4 1 2 3]
+	assert.Equal(t, slices.Collect(cb.Values()), []interface{}{1, 2, 3, 4})
+}
+
+func TestCircularBufferWindow(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	w, e := cb.Window(0, 2)
+	assert.Nil(t, e)
+	assert.Equal(t, w.ToArray(), []interface{}{2, 0})
+
+	_, e = cb.Window(2, 4)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+	_, e = cb.Window(2, 1)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+}
+
+func TestCircularBufferWriteCSV(t *testing.T) {
+	cb := NewCircularBuffer(2)
+	cb.PushBack(0) // [0 _]
+	cb.PushBack(1) // [0 1]
+	cb.PushBack(2) // [1 2]
+
+	row := func(v interface{}) []string {
+		return []string{strconv.Itoa(v.(int)), "x,y"}
+	}
+
+	var b strings.Builder
+	e := cb.WriteCSV(&b, []string{"n", "s"}, row)
+	assert.Nil(t, e)
+	assert.Equal(t, b.String(), "n,s\n1,\"x,y\"\n2,\"x,y\"\n")
+
+	b.Reset()
+	e = cb.WriteCSV(&b, nil, row)
+	assert.Nil(t, e)
+	assert.Equal(t, b.String(), "1,\"x,y\"\n2,\"x,y\"\n")
+}
+
+func TestCircularBufferWriteSnapshot(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)   // [0 _ _ _]
+	cb.PushBack("1") // [0 "1" _ _]
+	cb.PushBack(2)   // [0 "1" 2 _]
+	cb.PushBack(3)   // [0 "1" 2 3]
+	cb.PushBack(4)   // ["1" 2 3 4]
+	cb.PopBack()     // ["1" 2 3 _]
+
+	var b bytes.Buffer
+	e := cb.WriteSnapshot(&b)
+	assert.Nil(t, e)
+
+	var rcb CircularBuffer
+	e = rcb.ReadSnapshot(&b)
+	assert.Nil(t, e)
+	assert.Equal(t, rcb.buffer, cb.buffer)
+	assert.Equal(t, rcb.ToArray(), []interface{}{"1", 2, 3})
+	assert.Equal(t, rcb.NextSeq(), cb.NextSeq())
+
+	cb.SetCodec(StringCodec{})
+	e = cb.WriteSnapshot(&b)
+	assert.NotNil(t, e)
+}
+
 func BenchmarkCircularBuffer_PushBackUnderfill(b *testing.B) {
 	cb := NewCircularBuffer(b.N)
 
@@ -342,6 +2371,15 @@ func BenchmarkCircularBuffer_PushBackOverfill(b *testing.B) {
 	}
 }
 
+func BenchmarkCircularBuffer_PushBackOverfillNonPowerOfTwo(b *testing.B) {
+	cb := NewCircularBuffer(5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.PushBack(i)
+	}
+}
+
 func BenchmarkCircularBuffer_PushFrontOverfill(b *testing.B) {
 	cb := NewCircularBuffer(4)
 
@@ -349,4 +2387,78 @@ func BenchmarkCircularBuffer_PushFrontOverfill(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		cb.PushFront(i)
 	}
-}
\ No newline at end of file
+}
+
+func BenchmarkCircularBuffer_AppendTo(b *testing.B) {
+	cb := NewCircularBuffer(64)
+	for i := 0; i < 96; i++ {
+		cb.PushBack(i)
+	}
+	dst := make([]interface{}, 0, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = cb.AppendTo(dst[:0])
+	}
+}
+
+func BenchmarkCircularBuffer_PushBackSlice(b *testing.B) {
+	cb := NewCircularBuffer(1024)
+	vs := make([]interface{}, 256)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.PushBackSlice(vs)
+	}
+}
+
+func BenchmarkCircularBuffer_ElementTypes(b *testing.B) {
+	type point struct{ x, y, z float64 }
+	elements := map[string]interface{}{
+		"int":    42,
+		"string": "element",
+		"struct": point{1, 2, 3},
+	}
+	for _, name := range []string{"int", "string", "struct"} {
+		for _, capacity := range []int{1000, 1024} {
+			b.Run(fmt.Sprintf("%s/%d", name, capacity), func(b *testing.B) {
+				cb := NewCircularBuffer(capacity)
+				v := elements[name]
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					cb.PushBack(v)
+					cb.At(i % cb.Size())
+					if i%2 == 0 {
+						cb.PopFront()
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkCircularBuffer_Resize(b *testing.B) {
+	cb := NewCircularBuffer(4096)
+	for i := 0; i < 4096+1000; i++ {
+		cb.PushBack(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.Resize(4096 + i%2)
+		cb.PushBack(i)
+	}
+}
+
+func BenchmarkCircularBuffer_Clear(b *testing.B) {
+	cb := NewCircularBuffer(1 << 20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cb.PushBackSlice(make([]interface{}, cb.Capacity()))
+		b.StartTimer()
+		cb.Clear()
+	}
+}
@@ -0,0 +1,56 @@
+package gocontainers
+
+// CoalescingBuffer is a CircularBuffer where a pushed element replaces
+// the retained element with the same key instead of being appended.
+// There are no public members in this struct.
+type CoalescingBuffer struct {
+	cb    CircularBuffer
+	key   func(interface{}) interface{}
+	index map[interface{}]uint64 // key -> sequence number of its element
+}
+
+// NewCoalescingBuffer is the constructor function for CoalescingBuffer.
+// Keys returned by key must be comparable.
+func NewCoalescingBuffer(capacity int, key func(interface{}) interface{}) *CoalescingBuffer {
+	return &CoalescingBuffer{
+		cb:    NewCircularBuffer(capacity),
+		key:   key,
+		index: make(map[interface{}]uint64),
+	}
+}
+
+// FlushCoalesced removes all the elements from CoalescingBuffer
+// and returns them from the front to the back.
+func (c *CoalescingBuffer) FlushCoalesced() []interface{} {
+	elements := c.cb.ToArray()
+	c.cb.Clear()
+	c.index = make(map[interface{}]uint64)
+	return elements
+}
+
+// Push replaces the element with the same key keeping its position,
+// or appends value into the back. If CoalescingBuffer is full,
+// the front element is overwritten. Push reports whether value was coalesced.
+func (c *CoalescingBuffer) Push(value interface{}) bool {
+	k := c.key(value)
+	front := c.cb.NextSeq() - uint64(c.cb.Size())
+	if seq, ok := c.index[k]; ok && seq >= front {
+		c.cb.Set(int(seq-front), value)
+		return true
+	}
+
+	if c.cb.Full() {
+		v, _ := c.cb.Front()
+		if fk := c.key(v); c.index[fk] == front {
+			delete(c.index, fk)
+		}
+	}
+	c.index[k] = c.cb.NextSeq()
+	c.cb.PushBack(value)
+	return false
+}
+
+// Size returns number of elements in CoalescingBuffer.
+func (c *CoalescingBuffer) Size() int {
+	return c.cb.Size()
+}
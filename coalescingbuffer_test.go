@@ -0,0 +1,58 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type coalescingEvent struct {
+	id    string
+	value int
+}
+
+func newTestCoalescingBuffer(capacity int) *CoalescingBuffer {
+	return NewCoalescingBuffer(capacity, func(v interface{}) interface{} {
+		return v.(coalescingEvent).id
+	})
+}
+
+func TestCoalescingBufferFlushCoalesced(t *testing.T) {
+	c := newTestCoalescingBuffer(4)
+	assert.Empty(t, c.FlushCoalesced())
+
+	c.Push(coalescingEvent{"a", 0})
+	c.Push(coalescingEvent{"b", 1})
+	assert.Equal(t, c.FlushCoalesced(), []interface{}{coalescingEvent{"a", 0}, coalescingEvent{"b", 1}})
+	assert.Zero(t, c.Size())
+
+	assert.False(t, c.Push(coalescingEvent{"a", 2}))
+	assert.Equal(t, c.FlushCoalesced(), []interface{}{coalescingEvent{"a", 2}})
+}
+
+func TestCoalescingBufferPush(t *testing.T) {
+	c := newTestCoalescingBuffer(3)
+
+	assert.False(t, c.Push(coalescingEvent{"a", 0})) // [a0 _ _]
+	assert.False(t, c.Push(coalescingEvent{"b", 1})) // [a0 b1 _]
+	assert.True(t, c.Push(coalescingEvent{"a", 2}))  // [a2 b1 _]
+	assert.False(t, c.Push(coalescingEvent{"c", 3})) // [a2 b1 c3]
+	assert.False(t, c.Push(coalescingEvent{"d", 4})) // [b1 c3 d4]
+	assert.False(t, c.Push(coalescingEvent{"a", 5})) // [c3 d4 a5]
+	assert.True(t, c.Push(coalescingEvent{"d", 6}))  // [c3 d6 a5]
+	assert.Len(t, c.index, 3)
+
+	assert.Equal(t, c.FlushCoalesced(), []interface{}{
+		coalescingEvent{"c", 3},
+		coalescingEvent{"d", 6},
+		coalescingEvent{"a", 5},
+	})
+}
+
+func TestCoalescingBufferSize(t *testing.T) {
+	c := newTestCoalescingBuffer(3)
+	assert.Zero(t, c.Size())
+
+	c.Push(coalescingEvent{"a", 0})
+	c.Push(coalescingEvent{"a", 1})
+	assert.Equal(t, c.Size(), 1)
+}
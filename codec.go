@@ -0,0 +1,182 @@
+package gocontainers
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Versions of the snapshot format written by CircularBuffer.WriteSnapshot.
+const (
+	legacyVersion   = 1 // version, capacity, size, elements
+	snapshotVersion = 2 // magic, version, capacity, size, shift, sequence number, elements
+)
+
+// snapshotMagic starts every snapshot since snapshotVersion.
+var snapshotMagic = []byte("GOCB")
+
+// MaxDecodedCapacity limits capacity read by ReadSnapshot and its wrappers
+// and by UnmarshalJSON.
+// The backing array is allocated at once, so a larger capacity coming from
+// untrusted input is rejected instead of exhausting memory.
+const MaxDecodedCapacity = 1 << 24
+
+// Codec encodes and decodes elements for serialization of CircularBuffer.
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+// GobCodec encodes elements with encoding/gob. It is the default Codec.
+// Types other than the basic ones must be registered with gob.Register.
+type GobCodec struct{}
+
+// Encode implements Codec.
+func (GobCodec) Encode(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	e := gob.NewEncoder(&b).Encode(&v)
+	return b.Bytes(), e
+}
+
+// Decode implements Codec.
+func (GobCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	e := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, e
+}
+
+// StringCodec stores string elements as is.
+type StringCodec struct{}
+
+// Encode implements Codec.
+func (StringCodec) Encode(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a string", v)
+	}
+	return []byte(s), nil
+}
+
+// Decode implements Codec.
+func (StringCodec) Decode(data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+type fixedSizeCodec struct {
+	t    reflect.Type
+	size int
+}
+
+// NewFixedSizeCodec returns Codec for elements of the same type as sample,
+// which must be a fixed-size value accepted by encoding/binary.
+// Elements are stored in little-endian byte order.
+func NewFixedSizeCodec(sample interface{}) (Codec, error) {
+	size := binary.Size(sample)
+	if size < 0 || reflect.TypeOf(sample).Kind() == reflect.Ptr {
+		return nil, fmt.Errorf("%T is not a fixed-size value", sample)
+	}
+	return fixedSizeCodec{t: reflect.TypeOf(sample), size: size}, nil
+}
+
+// Encode implements Codec.
+func (c fixedSizeCodec) Encode(v interface{}) ([]byte, error) {
+	if reflect.TypeOf(v) != c.t {
+		return nil, fmt.Errorf("%T is not %v", v, c.t)
+	}
+	return binary.Append(make([]byte, 0, c.size), binary.LittleEndian, v)
+}
+
+// Decode implements Codec.
+func (c fixedSizeCodec) Decode(data []byte) (interface{}, error) {
+	if len(data) != c.size {
+		return nil, fmt.Errorf("%d bytes instead of %d", len(data), c.size)
+	}
+	v := reflect.New(c.t)
+	if _, e := binary.Decode(data, binary.LittleEndian, v.Interface()); e != nil {
+		return nil, e
+	}
+	return v.Elem().Interface(), nil
+}
+
+type binaryMarshalerCodec struct {
+	newElement func() encoding.BinaryUnmarshaler
+}
+
+// NewBinaryMarshalerCodec returns Codec for elements implementing encoding.BinaryMarshaler.
+// Elements are decoded into values returned by newElement.
+func NewBinaryMarshalerCodec(newElement func() encoding.BinaryUnmarshaler) Codec {
+	return binaryMarshalerCodec{newElement: newElement}
+}
+
+// Encode implements Codec.
+func (c binaryMarshalerCodec) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T is not encoding.BinaryMarshaler", v)
+	}
+	return m.MarshalBinary()
+}
+
+// Decode implements Codec.
+func (c binaryMarshalerCodec) Decode(data []byte) (interface{}, error) {
+	v := c.newElement()
+	if e := v.UnmarshalBinary(data); e != nil {
+		return nil, e
+	}
+	return v, nil
+}
+
+// appendElements appends length-prefixed encoded elements to b.
+func appendElements(b []byte, elements []interface{}, codec Codec) ([]byte, error) {
+	for _, v := range elements {
+		data, e := codec.Encode(v)
+		if e != nil {
+			return nil, e
+		}
+		b = binary.AppendUvarint(b, uint64(len(data)))
+		b = append(b, data...)
+	}
+	return b, nil
+}
+
+// readUvarints reads len(dst) uvarints from r into dst.
+func readUvarints(r *bytes.Reader, dst ...*uint64) error {
+	for _, d := range dst {
+		v, e := binary.ReadUvarint(r)
+		if e != nil {
+			return e
+		}
+		*d = v
+	}
+	return nil
+}
+
+// readElements reads n length-prefixed elements written by appendElements.
+func readElements(r *bytes.Reader, n uint64, codec Codec) ([]interface{}, error) {
+	var elements []interface{}
+	for i := uint64(0); i < n; i++ {
+		size, e := binary.ReadUvarint(r)
+		if e != nil {
+			return nil, e
+		}
+		if size > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		data := make([]byte, size)
+		r.Read(data)
+		v, e := codec.Decode(data)
+		if e != nil {
+			return nil, e
+		}
+		elements = append(elements, v)
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing data")
+	}
+	return elements, nil
+}
@@ -0,0 +1,86 @@
+package gocontainers
+
+import (
+	"encoding"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type codecPoint struct {
+	X, Y int32
+}
+
+func TestGobCodec(t *testing.T) {
+	var c Codec = GobCodec{}
+
+	for _, v := range []interface{}{1, "a", 2.5, []byte{1}} {
+		b, e := c.Encode(v)
+		assert.Nil(t, e)
+		d, e := c.Decode(b)
+		assert.Nil(t, e)
+		assert.Equal(t, d, v)
+	}
+
+	_, e := c.Encode(func() {})
+	assert.NotNil(t, e)
+	_, e = c.Decode([]byte{0})
+	assert.NotNil(t, e)
+}
+
+func TestStringCodec(t *testing.T) {
+	var c Codec = StringCodec{}
+
+	b, e := c.Encode("abc")
+	assert.Nil(t, e)
+	assert.Equal(t, b, []byte("abc"))
+
+	d, e := c.Decode(b)
+	assert.Nil(t, e)
+	assert.Equal(t, d, "abc")
+
+	_, e = c.Encode(1)
+	assert.NotNil(t, e)
+}
+
+func TestNewFixedSizeCodec(t *testing.T) {
+	c, e := NewFixedSizeCodec(codecPoint{})
+	assert.Nil(t, e)
+
+	b, e := c.Encode(codecPoint{1, -2})
+	assert.Nil(t, e)
+	assert.Equal(t, b, []byte{1, 0, 0, 0, 0xfe, 0xff, 0xff, 0xff})
+
+	d, e := c.Decode(b)
+	assert.Nil(t, e)
+	assert.Equal(t, d, codecPoint{1, -2})
+
+	_, e = c.Encode(int32(1))
+	assert.NotNil(t, e)
+	_, e = c.Decode(b[:4])
+	assert.NotNil(t, e)
+
+	_, e = NewFixedSizeCodec("a")
+	assert.NotNil(t, e)
+	_, e = NewFixedSizeCodec(&codecPoint{})
+	assert.NotNil(t, e)
+}
+
+func TestNewBinaryMarshalerCodec(t *testing.T) {
+	c := NewBinaryMarshalerCodec(func() encoding.BinaryUnmarshaler {
+		return new(time.Time)
+	})
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, e := c.Encode(now)
+	assert.Nil(t, e)
+
+	d, e := c.Decode(b)
+	assert.Nil(t, e)
+	assert.True(t, d.(*time.Time).Equal(now))
+
+	_, e = c.Encode(1)
+	assert.NotNil(t, e)
+	_, e = c.Decode(nil)
+	assert.NotNil(t, e)
+}
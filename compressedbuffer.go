@@ -0,0 +1,114 @@
+package gocontainers
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+)
+
+// Compressor compresses payloads stored in CompressedBuffer.
+type Compressor interface {
+	Compress(p []byte) ([]byte, error)
+	Decompress(p []byte) ([]byte, error)
+}
+
+// DeflateCompressor compresses payloads with compress/flate.
+type DeflateCompressor struct {
+	Level int // passed to flate.NewWriter
+}
+
+// Compress implements Compressor.
+func (dc DeflateCompressor) Compress(p []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w, e := flate.NewWriter(&b, dc.Level)
+	if e != nil {
+		return nil, e
+	}
+	if _, e = w.Write(p); e != nil {
+		return nil, e
+	}
+	if e = w.Close(); e != nil {
+		return nil, e
+	}
+	return b.Bytes(), nil
+}
+
+// Decompress implements Compressor.
+func (dc DeflateCompressor) Decompress(p []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(p))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// CompressedBuffer is a CircularBuffer of byte payloads stored compressed.
+// There are no public members in this struct.
+type CompressedBuffer struct {
+	cb               CircularBuffer
+	compressor       Compressor
+	uncompressedSize int
+	compressedSize   int
+}
+
+type compressedPayload struct {
+	data []byte
+	size int
+}
+
+// NewCompressedBuffer is the constructor function for CompressedBuffer.
+func NewCompressedBuffer(capacity int, compressor Compressor) *CompressedBuffer {
+	return &CompressedBuffer{cb: NewCircularBuffer(capacity), compressor: compressor}
+}
+
+// At returns decompressed payload from CompressedBuffer by index.
+func (c *CompressedBuffer) At(index int) ([]byte, error) {
+	v, e := c.cb.At(index)
+	if e != nil {
+		return nil, e
+	}
+	return c.compressor.Decompress(v.(compressedPayload).data)
+}
+
+// CompressedSize returns total size of stored payloads after compression.
+func (c *CompressedBuffer) CompressedSize() int {
+	return c.compressedSize
+}
+
+// PopFront removes front payload from CompressedBuffer.
+func (c *CompressedBuffer) PopFront() {
+	v, e := c.cb.Front()
+	if e != nil {
+		return
+	}
+	c.uncompressedSize -= v.(compressedPayload).size
+	c.compressedSize -= len(v.(compressedPayload).data)
+	c.cb.PopFront()
+}
+
+// PushBack compresses p and appends it into CompressedBuffer.
+// If CompressedBuffer is full, the front payload is overwritten.
+func (c *CompressedBuffer) PushBack(p []byte) error {
+	data, e := c.compressor.Compress(p)
+	if e != nil {
+		return e
+	}
+	if c.cb.Capacity() == 0 {
+		return nil
+	}
+	if c.cb.Full() {
+		c.PopFront()
+	}
+	c.cb.PushBack(compressedPayload{data: data, size: len(p)})
+	c.uncompressedSize += len(p)
+	c.compressedSize += len(data)
+	return nil
+}
+
+// Size returns number of payloads in CompressedBuffer.
+func (c *CompressedBuffer) Size() int {
+	return c.cb.Size()
+}
+
+// UncompressedSize returns total size of stored payloads before compression.
+func (c *CompressedBuffer) UncompressedSize() int {
+	return c.uncompressedSize
+}
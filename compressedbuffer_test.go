@@ -0,0 +1,98 @@
+package gocontainers
+
+import (
+	"bytes"
+	"compress/flate"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeflateCompressor(t *testing.T) {
+	dc := DeflateCompressor{Level: flate.BestCompression}
+	p := bytes.Repeat([]byte("abc"), 100)
+
+	c, e := dc.Compress(p)
+	assert.Nil(t, e)
+	assert.True(t, len(c) < len(p))
+
+	d, e := dc.Decompress(c)
+	assert.Nil(t, e)
+	assert.Equal(t, d, p)
+
+	_, e = DeflateCompressor{Level: 100}.Compress(p)
+	assert.NotNil(t, e)
+	_, e = dc.Decompress([]byte{0xff})
+	assert.NotNil(t, e)
+}
+
+func TestCompressedBufferAt(t *testing.T) {
+	c := NewCompressedBuffer(2, DeflateCompressor{Level: flate.DefaultCompression})
+
+	_, e := c.At(0)
+	assert.NotNil(t, e)
+
+	c.PushBack([]byte("a"))
+	c.PushBack([]byte("b"))
+	c.PushBack([]byte("c"))
+
+	p, e := c.At(0)
+	assert.Nil(t, e)
+	assert.Equal(t, p, []byte("b"))
+	p, e = c.At(1)
+	assert.Nil(t, e)
+	assert.Equal(t, p, []byte("c"))
+}
+
+func TestCompressedBufferCompressedSize(t *testing.T) {
+	c := NewCompressedBuffer(2, DeflateCompressor{Level: flate.BestCompression})
+	assert.Zero(t, c.CompressedSize())
+
+	c.PushBack(bytes.Repeat([]byte("a"), 1000))
+	assert.True(t, c.CompressedSize() > 0)
+	assert.True(t, c.CompressedSize() < 100)
+}
+
+func TestCompressedBufferPopFront(t *testing.T) {
+	c := NewCompressedBuffer(2, DeflateCompressor{Level: flate.DefaultCompression})
+	c.PopFront()
+
+	c.PushBack([]byte("ab"))
+	c.PopFront()
+	assert.Zero(t, c.Size())
+	assert.Zero(t, c.UncompressedSize())
+	assert.Zero(t, c.CompressedSize())
+}
+
+func TestCompressedBufferPushBack(t *testing.T) {
+	c := NewCompressedBuffer(2, DeflateCompressor{Level: flate.DefaultCompression})
+
+	assert.Nil(t, c.PushBack([]byte("a")))
+	assert.Nil(t, c.PushBack([]byte("bc")))
+	assert.Nil(t, c.PushBack([]byte("def")))
+	assert.Equal(t, c.Size(), 2)
+	assert.Equal(t, c.UncompressedSize(), 5)
+
+	c = NewCompressedBuffer(2, DeflateCompressor{Level: 100})
+	assert.NotNil(t, c.PushBack([]byte("a")))
+	assert.Zero(t, c.Size())
+	c = NewCompressedBuffer(0, DeflateCompressor{Level: flate.DefaultCompression})
+	assert.Nil(t, c.PushBack([]byte("a")))
+	assert.Zero(t, c.Size())
+	assert.Zero(t, c.UncompressedSize())
+}
+
+func TestCompressedBufferSize(t *testing.T) {
+	c := NewCompressedBuffer(2, DeflateCompressor{Level: flate.DefaultCompression})
+	assert.Zero(t, c.Size())
+
+	c.PushBack([]byte("a"))
+	assert.Equal(t, c.Size(), 1)
+}
+
+func TestCompressedBufferUncompressedSize(t *testing.T) {
+	c := NewCompressedBuffer(2, DeflateCompressor{Level: flate.DefaultCompression})
+	assert.Zero(t, c.UncompressedSize())
+
+	c.PushBack([]byte("abc"))
+	assert.Equal(t, c.UncompressedSize(), 3)
+}
@@ -0,0 +1,62 @@
+package gocontainers
+
+// Cursor remembers the position of a consumer in CircularBuffer by sequence number
+// and provides at-least-once delivery: elements are read again until acknowledged.
+// Cursor must not be used concurrently with modifications of CircularBuffer.
+type Cursor struct {
+	cb  *CircularBuffer
+	pos uint64
+}
+
+// NewCursor returns Cursor positioned at the front element of cb.
+func NewCursor(cb *CircularBuffer) *Cursor {
+	return &Cursor{cb: cb, pos: cb.seq}
+}
+
+// Ack marks elements with sequence numbers below seq as processed.
+// Cursor never moves backwards on Ack nor beyond CircularBuffer.NextSeq.
+func (c *Cursor) Ack(seq uint64) {
+	if next := c.cb.NextSeq(); seq > next {
+		seq = next
+	}
+	if seq > c.pos {
+		c.pos = seq
+	}
+}
+
+// Pos returns the sequence number of the first unacknowledged element.
+func (c *Cursor) Pos() uint64 {
+	return c.pos
+}
+
+// Read returns up to n unacknowledged elements (all of them if n <= 0)
+// without moving Cursor, and the number of elements removed from CircularBuffer
+// before they were read. Cursor skips the missed elements.
+func (c *Cursor) Read(n int) ([]interface{}, uint64) {
+	var missed uint64
+	if c.pos < c.cb.seq {
+		missed = c.cb.seq - c.pos
+		c.pos = c.cb.seq
+	}
+
+	// Cursor may be past the back after PopBack, then nothing is read.
+	count := 0
+	if next := c.cb.NextSeq(); c.pos < next {
+		count = int(next - c.pos)
+	}
+	start := c.cb.size - count
+	if n > 0 && n < count {
+		count = n
+	}
+	elements := make([]interface{}, count)
+	for i := range elements {
+		elements[i], _ = c.cb.At(start + i)
+	}
+	return elements, missed
+}
+
+// Seek moves Cursor to seq. It may be used to replay acknowledged elements.
+// Like Ack, Seek never moves Cursor beyond CircularBuffer.NextSeq.
+func (c *Cursor) Seek(seq uint64) {
+	c.pos = min(seq, c.cb.NextSeq())
+}
@@ -0,0 +1,94 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestCursorAck(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	c := NewCursor(&cb)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+
+	c.Ack(1)
+	assert.Equal(t, c.Pos(), uint64(1))
+
+	c.Ack(0)
+	assert.Equal(t, c.Pos(), uint64(1))
+
+	c.Ack(10)
+	assert.Equal(t, c.Pos(), uint64(2))
+}
+
+func TestCursorPos(t *testing.T) {
+	cb := NewCircularBuffer(2)
+	cb.PushBack(0) // [0 _]
+	cb.PushBack(1) // [0 1]
+	cb.PushBack(2) // [1 2]
+
+	c := NewCursor(&cb)
+	assert.Equal(t, c.Pos(), uint64(1))
+}
+
+func TestCursorRead(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	c := NewCursor(&cb)
+
+	v, missed := c.Read(0)
+	assert.Empty(t, v)
+	assert.Zero(t, missed)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	cb.PushBack(2) // [0 1 2 _]
+
+	v, missed = c.Read(2)
+	assert.Equal(t, v, []interface{}{0, 1})
+	assert.Zero(t, missed)
+
+	v, _ = c.Read(2)
+	assert.Equal(t, v, []interface{}{0, 1})
+
+	c.Ack(c.Pos() + uint64(len(v)))
+	v, _ = c.Read(0)
+	assert.Equal(t, v, []interface{}{2})
+
+	cb.PushBack(3) // [0 1 2 3]
+	cb.PushBack(4) // [1 2 3 4]
+	cb.PushBack(5) // [2 3 4 5]
+	cb.PushBack(6) // [3 4 5 6]
+	cb.PushBack(7) // [4 5 6 7]
+
+	v, missed = c.Read(0)
+	assert.Equal(t, v, []interface{}{4, 5, 6, 7})
+	assert.Equal(t, missed, uint64(2))
+	assert.Equal(t, c.Pos(), uint64(4))
+}
+
+func TestCursorSeek(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	c := NewCursor(&cb)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	c.Ack(2)
+
+	v, _ := c.Read(0)
+	assert.Empty(t, v)
+
+	c.Seek(1)
+	v, _ = c.Read(0)
+	assert.Equal(t, v, []interface{}{1})
+
+	c.Seek(math.MaxUint64)
+	assert.Equal(t, c.Pos(), uint64(2))
+	v, _ = c.Read(0)
+	assert.Empty(t, v)
+
+	cb.PopBack() // [0 _ _ _]
+	v, _ = c.Read(0)
+	assert.Empty(t, v)
+}
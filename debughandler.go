@@ -0,0 +1,49 @@
+package gocontainers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+type debugState struct {
+	Len        int           `json:"len"`
+	Cap        int           `json:"cap"`
+	Overwrites uint64        `json:"overwrites"`
+	Items      []interface{} `json:"items"`
+}
+
+func newDebugState(cb *CircularBuffer, mu sync.Locker, render func(interface{}) interface{}) debugState {
+	if mu != nil {
+		mu.Lock()
+	}
+	state := debugState{
+		Len:        cb.Size(),
+		Cap:        cb.Capacity(),
+		Overwrites: cb.Stats().Overwrites,
+		Items:      cb.ToArray(),
+	}
+	if mu != nil {
+		mu.Unlock()
+	}
+
+	if render != nil {
+		for i, v := range state.Items {
+			state.Items[i] = render(v)
+		}
+	}
+	return state
+}
+
+// DebugHandler returns http.Handler serving contents of CircularBuffer as JSON.
+// Each element is passed through render if it is not nil.
+// If mu is not nil, it is held while CircularBuffer is read.
+func DebugHandler(cb *CircularBuffer, mu sync.Locker, render func(interface{}) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		e := json.NewEncoder(w).Encode(newDebugState(cb, mu, render))
+		if e != nil {
+			http.Error(w, e.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package gocontainers
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+
+	w := httptest.NewRecorder()
+	DebugHandler(&cb, nil, nil).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, w.Body.String(), `{"len":2,"cap":4,"overwrites":0,"items":[0,1]}`+"\n")
+
+	var mu sync.Mutex
+	render := func(v interface{}) interface{} {
+		return fmt.Sprintf("#%d", v)
+	}
+	w = httptest.NewRecorder()
+	DebugHandler(&cb, &mu, render).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, w.Body.String(), `{"len":2,"cap":4,"overwrites":0,"items":["#0","#1"]}`+"\n")
+}
@@ -0,0 +1,13 @@
+package gocontainers
+
+// Deque is a double-ended queue. It has the same API as CircularBuffer,
+// but a push into full Deque doubles its capacity instead of overwriting,
+// so Overwrites in Stats stays zero and TryPushBack and TryPushFront never fail.
+type Deque struct {
+	CircularBuffer
+}
+
+// NewDeque is the constructor function for Deque with initial capacity.
+func NewDeque(capacity int) Deque {
+	return Deque{NewCircularBuffer(capacity, withGrowLimit(-1))}
+}
@@ -0,0 +1,65 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewDeque(t *testing.T) {
+	d := NewDeque(2)
+	assert.Equal(t, d.Capacity(), 2)
+	assert.True(t, d.Empty())
+}
+
+func TestDequePushBack(t *testing.T) {
+	d := NewDeque(2)
+
+	d.PushBack(0) // [0 _]
+	d.PushBack(1) // [0 1]
+	d.PushBack(2) // [0 1 2 _]
+	assert.Equal(t, d.Capacity(), 4)
+	assert.Equal(t, d.ToArray(), []interface{}{0, 1, 2})
+
+	d.PushBackSlice([]interface{}{3, 4, 5, 6, 7, 8, 9, 10}) // capacity 11
+	assert.Equal(t, d.Capacity(), 11)
+	assert.Equal(t, d.Size(), 11)
+	assert.Zero(t, d.Stats().Overwrites)
+	assert.Nil(t, d.CheckInvariants())
+
+	d = NewDeque(0)
+	d.PushBack(0) // [0]
+	d.PushBack(1) // [0 1]
+	assert.Equal(t, d.ToArray(), []interface{}{0, 1})
+}
+
+func TestDequePushFront(t *testing.T) {
+	d := NewDeque(2)
+
+	d.PushBack(1)  // [_ 1]
+	d.PushFront(0) // [0 1]
+	d.PopFront()   // [_ 1]
+	d.PushBack(2)  // [2 1]
+	d.PushFront(0) // [0 1 2 _]
+	assert.Equal(t, d.Capacity(), 4)
+	assert.Equal(t, d.ToArray(), []interface{}{0, 1, 2})
+
+	d.PushFrontSlice([]interface{}{-3, -2, -1}) // capacity 8
+	assert.Equal(t, d.Capacity(), 8)
+	assert.Equal(t, d.ToArray(), []interface{}{-3, -2, -1, 0, 1, 2})
+	assert.Nil(t, d.CheckInvariants())
+}
+
+func TestDequeTryPushBack(t *testing.T) {
+	d := NewDeque(1)
+	assert.Nil(t, d.TryPushBack(0))
+	assert.Nil(t, d.TryPushBack(1))
+	assert.Nil(t, d.TryPushFront(2))
+	assert.Equal(t, d.ToArray(), []interface{}{2, 0, 1})
+}
+
+func TestDequeUnmarshalJSON(t *testing.T) {
+	d := NewDeque(1)
+	e := d.UnmarshalJSON([]byte(`{"cap": 1, "items": ["a", "b"]}`))
+	assert.Nil(t, e)
+	assert.Equal(t, d.ToArray(), []interface{}{"a", "b"})
+}
@@ -0,0 +1,67 @@
+package gocontainers
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// TimedError is an error stored in ErrorRing along with the time it was added.
+type TimedError struct {
+	Time time.Time
+	Err  error
+}
+
+// Error prefixes the message of the wrapped error with the time.
+func (te TimedError) Error() string {
+	return te.Time.Format(time.RFC3339Nano) + " " + te.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (te TimedError) Unwrap() error {
+	return te.Err
+}
+
+// ErrorRing keeps the last N errors.
+// It is safe for concurrent use.
+type ErrorRing struct {
+	cb  CircularBuffer
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewErrorRing is the constructor function for ErrorRing.
+func NewErrorRing(n int) *ErrorRing {
+	return &ErrorRing{cb: NewCircularBuffer(n), now: time.Now}
+}
+
+// Add stores err. Nil errors are ignored.
+func (er *ErrorRing) Add(err error) {
+	if err == nil {
+		return
+	}
+	te := TimedError{Time: er.now(), Err: err}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	er.cb.PushBack(te)
+}
+
+// Err joins stored errors with errors.Join.
+// In case of empty ErrorRing nil returns.
+func (er *ErrorRing) Err() error {
+	return errors.Join(er.Errors()...)
+}
+
+// Errors returns stored errors from the oldest to the newest.
+// Each of them is a TimedError.
+func (er *ErrorRing) Errors() []error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	errs := make([]error, 0, er.cb.Size())
+	er.cb.Do(func(v interface{}) error {
+		errs = append(errs, v.(TimedError))
+		return nil
+	})
+	return errs
+}
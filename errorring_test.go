@@ -0,0 +1,52 @@
+package gocontainers
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func newTestErrorRing(n int) *ErrorRing {
+	er := NewErrorRing(n)
+	er.now = tickingClock()
+	return er
+}
+
+func TestErrorRingAdd(t *testing.T) {
+	er := newTestErrorRing(2)
+
+	er.Add(nil)
+	assert.Empty(t, er.Errors())
+
+	er.Add(io.EOF)
+	assert.Len(t, er.Errors(), 1)
+}
+
+func TestErrorRingErr(t *testing.T) {
+	er := newTestErrorRing(2)
+	assert.Nil(t, er.Err())
+
+	er.Add(errors.New("a"))
+	er.Add(io.EOF)
+	er.Add(io.ErrUnexpectedEOF)
+
+	e := er.Err()
+	assert.EqualError(t, e, "2020-01-01T00:00:02Z EOF\n2020-01-01T00:00:03Z unexpected EOF")
+	assert.True(t, errors.Is(e, io.EOF))
+	assert.True(t, errors.Is(e, io.ErrUnexpectedEOF))
+}
+
+func TestErrorRingErrors(t *testing.T) {
+	er := newTestErrorRing(2)
+
+	er.Add(errors.New("a"))
+	er.Add(io.EOF)
+	er.Add(io.ErrUnexpectedEOF)
+
+	assert.Equal(t, er.Errors(), []error{
+		TimedError{Time: time.Date(2020, 1, 1, 0, 0, 2, 0, time.UTC), Err: io.EOF},
+		TimedError{Time: time.Date(2020, 1, 1, 0, 0, 3, 0, time.UTC), Err: io.ErrUnexpectedEOF},
+	})
+}
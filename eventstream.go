@@ -0,0 +1,88 @@
+package gocontainers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// EventStream keeps the last N events and serves them over HTTP
+// as Server-Sent Events. It is safe for concurrent use.
+type EventStream struct {
+	cb     CircularBuffer
+	mu     sync.Mutex
+	notify chan struct{}
+	render func(interface{}) interface{}
+}
+
+// NewEventStream is the constructor function for EventStream.
+// Each event is passed through render if it is not nil and encoded as JSON.
+func NewEventStream(n int, render func(interface{}) interface{}) *EventStream {
+	return &EventStream{
+		cb:     NewCircularBuffer(n),
+		notify: make(chan struct{}),
+		render: render,
+	}
+}
+
+// Push appends new event and wakes up connected clients.
+func (es *EventStream) Push(event interface{}) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.cb.PushBack(event)
+	close(es.notify)
+	es.notify = make(chan struct{})
+}
+
+// ServeHTTP replays stored events and then streams new ones until the client disconnects.
+// Event ids are sequence numbers, so a reconnecting client sending Last-Event-ID
+// receives only the events it has not seen yet.
+func (es *EventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var next uint64
+	if id, e := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64); e == nil {
+		next = id + 1
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		es.mu.Lock()
+		events, seq := es.cb.ReadSince(next)
+		notify := es.notify
+		es.mu.Unlock()
+
+		first := seq - uint64(len(events))
+		for i, event := range events {
+			if es.render != nil {
+				event = es.render(event)
+			}
+			data, e := json.Marshal(event)
+			if e != nil {
+				data, _ = json.Marshal(e.Error())
+			}
+			_, e = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", first+uint64(i), data)
+			if e != nil {
+				return
+			}
+		}
+		flusher.Flush()
+		next = seq
+
+		select {
+		case <-notify:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gocontainers
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	var lines []string
+	for {
+		line, e := r.ReadString('\n')
+		assert.Nil(t, e)
+		if line == "\n" || e != nil {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestEventStreamPush(t *testing.T) {
+	es := NewEventStream(2, nil)
+	es.Push(0)
+	es.Push(1)
+	es.Push(2)
+
+	v, next := es.cb.ReadSince(0)
+	assert.Equal(t, v, []interface{}{1, 2})
+	assert.Equal(t, next, uint64(3))
+}
+
+func TestEventStreamServeHTTP(t *testing.T) {
+	es := NewEventStream(2, func(v interface{}) interface{} {
+		return map[string]interface{}{"n": v}
+	})
+	es.Push(0)
+	es.Push(1)
+	es.Push(2)
+
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	response, e := http.DefaultClient.Do(request)
+	assert.Nil(t, e)
+	defer response.Body.Close()
+	assert.Equal(t, response.Header.Get("Content-Type"), "text/event-stream")
+
+	r := bufio.NewReader(response.Body)
+	assert.Equal(t, readEvent(t, r), "id: 1\ndata: {\"n\":1}\n")
+	assert.Equal(t, readEvent(t, r), "id: 2\ndata: {\"n\":2}\n")
+
+	es.Push(3)
+	assert.Equal(t, readEvent(t, r), "id: 3\ndata: {\"n\":3}\n")
+
+	request, _ = http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	request.Header.Set("Last-Event-ID", "2")
+	response, e = http.DefaultClient.Do(request)
+	assert.Nil(t, e)
+	defer response.Body.Close()
+
+	r = bufio.NewReader(response.Body)
+	assert.Equal(t, readEvent(t, r), "id: 3\ndata: {\"n\":3}\n")
+}
@@ -0,0 +1,30 @@
+package gocontainers
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// ExpVar wraps CircularBuffer to implement expvar.Var.
+// It renders the same JSON document as DebugHandler.
+type ExpVar struct {
+	cb     *CircularBuffer
+	mu     sync.Locker
+	format func(interface{}) interface{}
+}
+
+// NewExpVar is the constructor function for ExpVar.
+// Each element is passed through format if it is not nil.
+// If mu is not nil, it is held while CircularBuffer is read.
+func NewExpVar(cb *CircularBuffer, mu sync.Locker, format func(interface{}) interface{}) *ExpVar {
+	return &ExpVar{cb: cb, mu: mu, format: format}
+}
+
+// String returns contents and occupancy of CircularBuffer as JSON.
+func (ev *ExpVar) String() string {
+	b, e := json.Marshal(newDebugState(ev.cb, ev.mu, ev.format))
+	if e != nil {
+		b, _ = json.Marshal(e.Error())
+	}
+	return string(b)
+}
@@ -0,0 +1,26 @@
+package gocontainers
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpVarString(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	var v expvar.Var = NewExpVar(&cb, nil, nil)
+	assert.Equal(t, v.String(), `{"len":0,"cap":4,"overwrites":0,"items":[]}`)
+
+	cb.PushBack(0) // [0 _ _ _]
+	cb.PushBack(1) // [0 1 _ _]
+	assert.Equal(t, v.String(), `{"len":2,"cap":4,"overwrites":0,"items":[0,1]}`)
+
+	v = NewExpVar(&cb, nil, func(v interface{}) interface{} {
+		return fmt.Sprintf("#%d", v)
+	})
+	assert.Equal(t, v.String(), `{"len":2,"cap":4,"overwrites":0,"items":["#0","#1"]}`)
+
+	cb.PushBack(func() {})
+	assert.Equal(t, NewExpVar(&cb, nil, nil).String(), `"json: unsupported type: func()"`)
+}
@@ -0,0 +1,145 @@
+package gocontainers
+
+import (
+	"cmp"
+	"hash/maphash"
+	"math"
+)
+
+// Number is a constraint for integer and floating-point element types.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Summary holds aggregates of numeric elements computed by Summarize.
+// Variance and StdDev are population ones.
+type Summary struct {
+	Count    int
+	Sum      float64
+	Mean     float64
+	Variance float64
+	StdDev   float64
+}
+
+// Compare compares elements of CircularBuffers a and b lexicographically
+// like slices.Compare. Elements must be of type T.
+func Compare[T cmp.Ordered](a, b *CircularBuffer) int {
+	return CompareFunc(a, b, compare[T])
+}
+
+// GroupBy splits elements of CircularBuffer into new CircularBuffers by key.
+// Each of them has the capacity of CircularBuffer and keeps the order of elements.
+func GroupBy[K comparable](cb *CircularBuffer, key func(interface{}) K) map[K]*CircularBuffer {
+	groups := make(map[K]*CircularBuffer)
+	for v := range cb.Values() {
+		k := key(v)
+		group, ok := groups[k]
+		if !ok {
+			g := NewCircularBuffer(cb.capacity)
+			group = &g
+			groups[k] = group
+		}
+		*group.cell(group.size) = v
+		group.size++
+	}
+	for _, group := range groups {
+		group.updateMaxSize()
+	}
+	return groups
+}
+
+// Hash returns a hash of elements of CircularBuffer, which doesn't depend
+// on their placement in the backing array. Equal contents give equal hashes
+// for the same seed. Elements must be of type T.
+func Hash[T comparable](cb *CircularBuffer, seed maphash.Seed) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	maphash.WriteComparable(&h, cb.size)
+	for v := range cb.Values() {
+		maphash.WriteComparable(&h, v.(T))
+	}
+	return h.Sum64()
+}
+
+// IsSorted checks if elements of CircularBuffer are sorted in ascending order.
+// Elements must be of type T.
+func IsSorted[T cmp.Ordered](cb *CircularBuffer) bool {
+	return cb.IsSortedFunc(compare[T])
+}
+
+// Max returns the first maximal element of CircularBuffer and its index.
+// It reports false if CircularBuffer is empty. Elements must be of type T.
+func Max[T cmp.Ordered](cb *CircularBuffer) (T, int, bool) {
+	value, index, ok := cb.MaxFunc(compare[T])
+	if !ok {
+		var zero T
+		return zero, index, false
+	}
+	return value.(T), index, true
+}
+
+// Min returns the first minimal element of CircularBuffer and its index.
+// It reports false if CircularBuffer is empty. Elements must be of type T.
+func Min[T cmp.Ordered](cb *CircularBuffer) (T, int, bool) {
+	value, index, ok := cb.MinFunc(compare[T])
+	if !ok {
+		var zero T
+		return zero, index, false
+	}
+	return value.(T), index, true
+}
+
+// compare compares elements of type T stored in interface values.
+func compare[T cmp.Ordered](a, b interface{}) int {
+	return cmp.Compare(a.(T), b.(T))
+}
+
+// Reduce folds elements of CircularBuffer from the front to the back into an accumulator
+// starting with init and returns the result.
+func Reduce[A any](cb *CircularBuffer, init A, f func(A, interface{}) A) A {
+	acc := init
+	for v := range cb.Values() {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// ReduceBackward is like Reduce, but folds elements from the back to the front.
+func ReduceBackward[A any](cb *CircularBuffer, init A, f func(A, interface{}) A) A {
+	acc := init
+	for _, v := range cb.Backward() {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Sum returns the sum of elements of CircularBuffer. Elements must be of type T.
+func Sum[T Number](cb *CircularBuffer) T {
+	var sum T
+	for v := range cb.Values() {
+		sum += v.(T)
+	}
+	return sum
+}
+
+// Summarize computes Summary of elements of CircularBuffer in one pass
+// with Welford's algorithm. Elements must be of type T.
+func Summarize[T Number](cb *CircularBuffer) Summary {
+	var s Summary
+	var m2 float64
+	for v := range cb.Values() {
+		x := float64(v.(T))
+		s.Count++
+		s.Sum += x
+		delta := x - s.Mean
+		s.Mean += delta / float64(s.Count)
+		m2 += delta * (x - s.Mean)
+	}
+	if s.Count > 0 {
+		s.Variance = m2 / float64(s.Count)
+		s.StdDev = math.Sqrt(s.Variance)
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"hash/maphash"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	a := NewCircularBufferWithValues(3, 1, 2, 3)
+	b := NewCircularBuffer(3)
+
+	b.PushBack(2)  // [2 _ _]
+	b.PushBack(3)  // [2 3 _]
+	b.PushFront(1) // [2 3 1]
+
+	assert.Equal(t, Compare[int](&a, &b), 0)
+	b.PopBack() // [_ 2 1]
+	assert.Equal(t, Compare[int](&a, &b), 1)
+	assert.Equal(t, Compare[int](&b, &a), -1)
+	b.PushBack(4) // [4 2 1]
+	assert.Equal(t, Compare[int](&a, &b), -1)
+}
+
+func TestGroupBy(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Empty(t, GroupBy(&cb, func(v interface{}) int { return v.(int) % 2 }))
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(2)  // [1 2 _ _]
+	cb.PushBack(4)  // [1 2 4 _]
+	cb.PushFront(3) // [1 2 4 3]
+
+	groups := GroupBy(&cb, func(v interface{}) bool { return v.(int)%2 == 0 })
+	assert.Len(t, groups, 2)
+	assert.Equal(t, groups[false].ToArray(), []interface{}{3, 1})
+	assert.Equal(t, groups[true].ToArray(), []interface{}{2, 4})
+	assert.Equal(t, groups[true].Capacity(), 4)
+	assert.Nil(t, groups[true].CheckInvariants())
+}
+
+func TestHash(t *testing.T) {
+	seed := maphash.MakeSeed()
+	a := NewCircularBufferWithValues(3, "x", "y")
+	b := NewCircularBuffer(4)
+
+	b.PushBack("y")  // [y _ _ _]
+	b.PushFront("x") // [y _ _ x]
+
+	assert.Equal(t, Hash[string](&a, seed), Hash[string](&b, seed))
+	b.PushBack("z") // [y z _ x]
+	assert.NotEqual(t, Hash[string](&a, seed), Hash[string](&b, seed))
+
+	empty := NewCircularBuffer(0)
+	assert.Equal(t, Hash[string](&empty, seed), Hash[int](&empty, seed))
+}
+
+func TestIsSorted(t *testing.T) {
+	cb := NewCircularBuffer(3)
+	assert.True(t, IsSorted[int](&cb))
+
+	cb.PushBack(2)  // [2 _ _]
+	cb.PushBack(2)  // [2 2 _]
+	cb.PushFront(1) // [2 2 1]
+	assert.True(t, IsSorted[int](&cb))
+
+	cb.PushBack(0) // [0 2 2]
+	assert.False(t, IsSorted[int](&cb))
+}
+
+func TestMax(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	_, index, ok := Max[int](&cb)
+	assert.Equal(t, index, -1)
+	assert.False(t, ok)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(3)  // [1 3 _ _]
+	cb.PushFront(3) // [1 3 _ 3]
+	cb.PushFront(2) // [1 3 2 3]
+
+	value, index, ok := Max[int](&cb)
+	assert.Equal(t, value, 3)
+	assert.Equal(t, index, 1)
+	assert.True(t, ok)
+}
+
+func TestMin(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, "b", "a", "c", "a")
+
+	value, index, ok := Min[string](&cb)
+	assert.Equal(t, value, "a")
+	assert.Equal(t, index, 1)
+	assert.True(t, ok)
+	assert.Panics(t, func() { Min[int](&cb) })
+}
+
+func TestReduce(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	join := func(acc string, v interface{}) string { return acc + v.(string) }
+	assert.Equal(t, Reduce(&cb, "-", join), "-")
+
+	cb.PushBack("a")  // [a _ _ _]
+	cb.PushBack("b")  // [a b _ _]
+	cb.PushFront("c") // [a b _ c]
+	assert.Equal(t, Reduce(&cb, "-", join), "-cab")
+	assert.Equal(t, Reduce(&cb, 0, func(acc int, v interface{}) int { return acc + len(v.(string)) }), 3)
+}
+
+func TestReduceBackward(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	join := func(acc string, v interface{}) string { return acc + v.(string) }
+	assert.Equal(t, ReduceBackward(&cb, "-", join), "-")
+
+	cb.PushBack("a")  // [a _ _ _]
+	cb.PushBack("b")  // [a b _ _]
+	cb.PushFront("c") // [a b _ c]
+	assert.Equal(t, ReduceBackward(&cb, "-", join), "-bac")
+}
+
+func TestSum(t *testing.T) {
+	cb := NewCircularBuffer(4)
+	assert.Equal(t, Sum[int](&cb), 0)
+
+	cb.PushBack(1)  // [1 _ _ _]
+	cb.PushBack(2)  // [1 2 _ _]
+	cb.PushFront(3) // [1 2 _ 3]
+	assert.Equal(t, Sum[int](&cb), 6)
+
+	cb = NewCircularBufferWithValues(2, 0.5, 0.25)
+	assert.Equal(t, Sum[float64](&cb), 0.75)
+}
+
+func TestSummarize(t *testing.T) {
+	cb := NewCircularBuffer(8)
+	assert.Equal(t, Summarize[int](&cb), Summary{})
+
+	for _, v := range []int{2, 4, 4, 4, 5, 5, 7, 9} {
+		cb.PushBack(v)
+	}
+	assert.Equal(t, Summarize[int](&cb), Summary{Count: 8, Sum: 40, Mean: 5, Variance: 4, StdDev: 2})
+
+	cb.PushBack(1) // [1 4 4 4 5 5 7 9]
+	s := Summarize[int](&cb)
+	assert.Equal(t, s.Count, 8)
+	assert.Equal(t, s.Sum, 39.0)
+	assert.InDelta(t, s.Mean, 4.875, 1e-9)
+	assert.InDelta(t, s.Variance, 4.859375, 1e-9)
+}
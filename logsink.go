@@ -0,0 +1,65 @@
+package gocontainers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogRecord is a message stored in LogSink.
+type LogRecord struct {
+	Time    time.Time
+	Message string
+}
+
+// LogSink is an io.Writer for log.Logger which keeps the last N messages.
+// It is safe for concurrent use.
+type LogSink struct {
+	cb  CircularBuffer
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewLogSink is the constructor function for LogSink.
+func NewLogSink(n int) *LogSink {
+	return &LogSink{cb: NewCircularBuffer(n), now: time.Now}
+}
+
+// Dump writes stored messages into w, one per line, prefixed with timestamps.
+func (ls *LogSink) Dump(w io.Writer) error {
+	for _, r := range ls.Records() {
+		_, e := fmt.Fprintf(w, "%s %s\n", r.Time.Format(time.RFC3339Nano), r.Message)
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Records returns stored messages from the oldest to the newest.
+func (ls *LogSink) Records() []LogRecord {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	records := make([]LogRecord, 0, ls.cb.Size())
+	ls.cb.Do(func(v interface{}) error {
+		records = append(records, v.(LogRecord))
+		return nil
+	})
+	return records
+}
+
+// Write stores p as a single message without the trailing newline.
+// It never fails.
+func (ls *LogSink) Write(p []byte) (int, error) {
+	r := LogRecord{
+		Time:    ls.now(),
+		Message: strings.TrimSuffix(string(p), "\n"),
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.cb.PushBack(r)
+	return len(p), nil
+}
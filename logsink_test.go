@@ -0,0 +1,53 @@
+package gocontainers
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func newTestLogSink(n int) *LogSink {
+	ls := NewLogSink(n)
+	ls.now = tickingClock()
+	return ls
+}
+
+func TestLogSinkDump(t *testing.T) {
+	ls := newTestLogSink(2)
+	logger := log.New(ls, "", 0)
+
+	logger.Print("a")
+	logger.Print("b")
+	logger.Print("c")
+
+	var b bytes.Buffer
+	e := ls.Dump(&b)
+	assert.Nil(t, e)
+	assert.Equal(t, b.String(), "2020-01-01T00:00:02Z b\n2020-01-01T00:00:03Z c\n")
+}
+
+func TestLogSinkRecords(t *testing.T) {
+	ls := newTestLogSink(2)
+	assert.Empty(t, ls.Records())
+
+	logger := log.New(ls, "", 0)
+	logger.Print("a")
+	logger.Print("b")
+	logger.Print("c")
+
+	assert.Equal(t, ls.Records(), []LogRecord{
+		{Time: time.Date(2020, 1, 1, 0, 0, 2, 0, time.UTC), Message: "b"},
+		{Time: time.Date(2020, 1, 1, 0, 0, 3, 0, time.UTC), Message: "c"},
+	})
+}
+
+func TestLogSinkWrite(t *testing.T) {
+	ls := newTestLogSink(2)
+
+	n, e := ls.Write([]byte("a\n"))
+	assert.Equal(t, n, 2)
+	assert.Nil(t, e)
+	assert.Equal(t, ls.Records()[0].Message, "a")
+}
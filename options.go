@@ -0,0 +1,107 @@
+package gocontainers
+
+import "math/bits"
+
+// Option configures CircularBuffer in NewCircularBuffer.
+type Option func(*CircularBuffer)
+
+// OverwritePolicy selects what a push into full CircularBuffer does.
+type OverwritePolicy int
+
+const (
+	// Overwrite drops the element at the opposite end to make room. It is the default.
+	Overwrite OverwritePolicy = iota
+	// Discard drops the pushed element and keeps CircularBuffer unchanged.
+	Discard
+)
+
+// WithAutoShrink makes CircularBuffer halve its capacity, releasing the backing array,
+// when it is filled below threshold (a fraction of capacity) after each of the last
+// n removals of elements. Capacity never shrinks below the capacity passed to
+// NewCircularBuffer. It is meant for CircularBuffer that grows, see WithGrowUpTo.
+// Non-positive n disables shrinking.
+func WithAutoShrink(threshold float64, n int) Option {
+	return func(cb *CircularBuffer) {
+		cb.shrink = shrinkPolicy{threshold: threshold, after: max(n, 0), floor: cb.capacity}
+	}
+}
+
+// WithCodec sets the codec like SetCodec.
+func WithCodec(codec Codec) Option {
+	return func(cb *CircularBuffer) {
+		cb.codec = codec
+	}
+}
+
+// WithEvictionCallback sets f to be called with every element dropped by a push
+// into full CircularBuffer, whichever end it is dropped from.
+// Elements removed by pops, Clear or Resize are not passed to f.
+func WithEvictionCallback(f func(interface{})) Option {
+	return func(cb *CircularBuffer) {
+		cb.onEvict = f
+	}
+}
+
+// withGrowLimit sets the maximum capacity reached by growth.
+func withGrowLimit(limit int) Option {
+	return func(cb *CircularBuffer) {
+		cb.growLimit = limit
+	}
+}
+
+// WithGrowUpTo makes CircularBuffer start with the capacity passed to
+// NewCircularBuffer and double it when a push finds it full, up to maxCapacity.
+// Only a push into full CircularBuffer of capacity maxCapacity drops elements.
+// Non-positive maxCapacity disables growth.
+func WithGrowUpTo(maxCapacity int) Option {
+	return withGrowLimit(max(maxCapacity, 0))
+}
+
+// WithLazyAllocation defers allocation of the backing array until the first push,
+// so CircularBuffer which is never used holds no memory for elements.
+func WithLazyAllocation() Option {
+	return func(cb *CircularBuffer) {
+		cb.lazy = true
+	}
+}
+
+// WithOccupancyHistogram enables the occupancy histogram like EnableOccupancyHistogram.
+func WithOccupancyHistogram() Option {
+	return func(cb *CircularBuffer) {
+		cb.EnableOccupancyHistogram()
+	}
+}
+
+// WithPowerOfTwoCapacity rounds the capacity passed to NewCircularBuffer up to
+// a power of two, so index arithmetic uses a bit mask instead of division.
+func WithPowerOfTwoCapacity() Option {
+	return func(cb *CircularBuffer) {
+		cb.pow2 = true
+		if cb.capacity > 0 {
+			cb.setCapacity(1 << bits.Len(uint(cb.capacity-1)))
+		}
+	}
+}
+
+// WithOverwritePolicy sets the overwrite policy.
+func WithOverwritePolicy(policy OverwritePolicy) Option {
+	return func(cb *CircularBuffer) {
+		cb.policy = policy
+	}
+}
+
+// WithSegmentedStorage stores elements in blocks of blockSize slots instead of
+// a single backing array, so huge capacities don't need a contiguous allocation
+// and Resize adds or drops whole blocks instead of copying all elements.
+// Capacity is rounded up to whole blocks internally, but Capacity and overwriting
+// still follow the requested one. Slices returns a copy and Raw panics then.
+// If blockSize is not positive, WithSegmentedStorage panics.
+func WithSegmentedStorage(blockSize int) Option {
+	if blockSize <= 0 {
+		panic("gocontainers: WithSegmentedStorage: non-positive block size")
+	}
+	return func(cb *CircularBuffer) {
+		cb.blockSize = blockSize
+		cb.setCapacity(cb.capacity)
+	}
+}
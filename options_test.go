@@ -0,0 +1,322 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestWithAutoShrink(t *testing.T) {
+	cb := NewCircularBuffer(2, WithGrowUpTo(16), WithAutoShrink(0.25, 2))
+	for i := 0; i < 16; i++ {
+		cb.PushBack(i)
+	}
+	assert.Equal(t, cb.Capacity(), 16)
+
+	dst := make([]interface{}, 13)
+	cb.PopFrontInto(dst) // 3 of 16
+	assert.Equal(t, cb.Capacity(), 16)
+	cb.PopFront() // 2 of 16
+	assert.Equal(t, cb.Capacity(), 8)
+	assert.Equal(t, cb.ToArray(), []interface{}{14, 15})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.PushBack(16) // 3 of 8
+	cb.PopFront()   // 2 of 8
+	cb.PushBack(17) // 3 of 8
+	cb.PopFront()   // 2 of 8
+	assert.Equal(t, cb.Capacity(), 8)
+
+	cb.Clear() // 0 of 8
+	cb.Clear() // 0 of 4
+	assert.Equal(t, cb.Capacity(), 4)
+	cb.Clear()
+	cb.Clear() // 0 of 2
+	cb.Clear()
+	cb.Clear()
+	assert.Equal(t, cb.Capacity(), 2)
+}
+
+func TestWithCodec(t *testing.T) {
+	cb := NewCircularBuffer(2, WithCodec(StringCodec{}))
+	cb.PushBack("a")
+
+	data, e := cb.Snapshot()
+	assert.Nil(t, e)
+
+	cb.PushBack(0)
+	e = cb.Restore(data)
+	assert.Nil(t, e)
+	assert.Equal(t, cb.ToArray(), []interface{}{"a"})
+}
+
+func TestWithEvictionCallback(t *testing.T) {
+	var evicted []interface{}
+	cb := NewCircularBuffer(2, WithEvictionCallback(func(v interface{}) {
+		evicted = append(evicted, v)
+	}))
+
+	cb.PushBack(0)  // [0 _]
+	cb.PushBack(1)  // [0 1]
+	cb.PushBack(2)  // [2 1]
+	cb.PushFront(3) // [2 3]
+	cb.PopBack()    // [_ 3]
+	assert.Equal(t, evicted, []interface{}{0, 2})
+
+	evicted = nil
+	cb.PushBackSlice([]interface{}{4, 5, 6}) // [6 5]
+	assert.Equal(t, evicted, []interface{}{3, 4})
+
+	evicted = nil
+	cb.PushFrontSlice([]interface{}{7, 8, 9}) // [7 8]
+	assert.Equal(t, evicted, []interface{}{6, 5, 9})
+
+	cb.UnmarshalJSON([]byte(`[1, 2, 3]`))
+	assert.Equal(t, cb.ToArray(), []interface{}{2.0, 3.0})
+	assert.Equal(t, evicted, []interface{}{6, 5, 9, 1.0})
+}
+
+func TestWithGrowUpTo(t *testing.T) {
+	cb := NewCircularBuffer(1, WithGrowUpTo(5))
+
+	cb.PushBack(0) // [0]
+	cb.PushBack(1) // [0 1]
+	cb.PushBack(2) // [0 1 2 _]
+	assert.Equal(t, cb.Capacity(), 4)
+	assert.Nil(t, cb.TryPushBack(3)) // [0 1 2 3]
+	assert.Nil(t, cb.TryPushBack(4)) // [0 1 2 3 4]
+	assert.Equal(t, cb.Capacity(), 5)
+	assert.ErrorIs(t, cb.TryPushBack(5), ErrFull)
+
+	cb.PushBack(5) // [5 1 2 3 4]
+	assert.Equal(t, cb.Capacity(), 5)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3, 4, 5})
+	assert.Equal(t, cb.Stats().Overwrites, uint64(1))
+
+	cb = NewCircularBuffer(2, WithGrowUpTo(3))
+	n := cb.PushBackSlice([]interface{}{0, 1, 2, 3}) // [3 1 2]
+	assert.Equal(t, n, 1)
+	assert.Equal(t, cb.ToArray(), []interface{}{1, 2, 3})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb = NewCircularBuffer(2, WithGrowUpTo(1))
+	cb.PushBackSlice([]interface{}{0, 1, 2}) // [2 1]
+	assert.Equal(t, cb.Capacity(), 2)
+
+	cb = NewCircularBuffer(0, WithGrowUpTo(2), WithLazyAllocation())
+	cb.PushFront(1) // [1]
+	cb.PushFront(0) // [0 1]
+	cb.PushFront(2) // [2 0]
+	assert.Equal(t, cb.ToArray(), []interface{}{2, 0})
+}
+
+func TestWithLazyAllocation(t *testing.T) {
+	cb := NewCircularBuffer(4, WithLazyAllocation())
+	assert.Nil(t, cb.buffer)
+	assert.Equal(t, cb.Capacity(), 4)
+	assert.Empty(t, cb.ToArray())
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.Resize(2)
+	assert.Nil(t, cb.buffer)
+	assert.Equal(t, cb.Capacity(), 2)
+
+	cb.PushBack(0) // [0 _]
+	assert.Equal(t, len(cb.buffer), 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{0})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb = NewCircularBuffer(2, WithLazyAllocation())
+	buf, _, _ := cb.Raw()
+	assert.Equal(t, len(buf), 2)
+
+	cb = NewCircularBuffer(2, WithLazyAllocation())
+	cb.PushFrontSlice([]interface{}{0, 1}) // [0 1]
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1})
+}
+
+func TestWithOccupancyHistogram(t *testing.T) {
+	cb := NewCircularBuffer(2, WithOccupancyHistogram())
+	cb.PushBack(0)
+	assert.Equal(t, cb.OccupancyHistogram(), []uint64{0, 1, 0})
+}
+
+func TestWithPowerOfTwoCapacity(t *testing.T) {
+	cb := NewCircularBuffer(5, WithPowerOfTwoCapacity())
+	assert.Equal(t, cb.Capacity(), 8)
+	assert.Equal(t, cb.mask, 7)
+
+	for i := 0; i < 10; i++ {
+		cb.PushBack(i)
+	}
+	cb.PushFront(-1)
+	assert.Equal(t, cb.ToArray(), []interface{}{-1, 2, 3, 4, 5, 6, 7, 8})
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb = NewCircularBuffer(4, WithPowerOfTwoCapacity())
+	assert.Equal(t, cb.Capacity(), 4)
+
+	cb = NewCircularBuffer(0, WithPowerOfTwoCapacity())
+	assert.Equal(t, cb.Capacity(), 0)
+
+	cb.Resize(3)
+	assert.Equal(t, cb.mask, -1)
+}
+
+func TestWithOverwritePolicy(t *testing.T) {
+	var evicted []interface{}
+	cb := NewCircularBuffer(2, WithOverwritePolicy(Discard), WithEvictionCallback(func(v interface{}) {
+		evicted = append(evicted, v)
+	}))
+
+	cb.PushBack(0)  // [0 _]
+	cb.PushBack(1)  // [0 1]
+	cb.PushBack(2)  // [0 1]
+	cb.PushFront(3) // [0 1]
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 1})
+	assert.Equal(t, evicted, []interface{}{2, 3})
+	assert.Equal(t, cb.NextSeq(), uint64(2))
+
+	cb.PopBack() // [0 _]
+	evicted = nil
+	n := cb.PushBackSlice([]interface{}{4, 5, 6}) // [0 4]
+	assert.Equal(t, n, 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{0, 4})
+	assert.Equal(t, evicted, []interface{}{5, 6})
+
+	cb.PopFront() // [_ 4]
+	evicted = nil
+	n = cb.PushFrontSlice([]interface{}{7, 8, 9}) // [9 4]
+	assert.Equal(t, n, 2)
+	assert.Equal(t, cb.ToArray(), []interface{}{9, 4})
+	assert.Equal(t, evicted, []interface{}{8, 7})
+	assert.Nil(t, cb.CheckInvariants())
+
+	stats := cb.Stats()
+	assert.Equal(t, stats.PushBacks, uint64(6))
+	assert.Equal(t, stats.PushFronts, uint64(4))
+	assert.Equal(t, stats.Overwrites, uint64(6))
+}
+
+func TestWithSegmentedStorage(t *testing.T) {
+	cb := NewCircularBuffer(1000000, WithSegmentedStorage(4), WithLazyAllocation())
+	assert.Equal(t, cb.Capacity(), 1000000)
+	assert.Nil(t, cb.blocks)
+	assert.Panics(t, func() {
+		WithSegmentedStorage(0)
+	})
+
+	cb = NewCircularBuffer(6, WithSegmentedStorage(4))
+	assert.Equal(t, len(cb.blocks), 2)
+	cb.PushBackSlice(slices.Collect(intSeq(9))) // [3 4 5 6 | 7 8 _ _]
+	cb.PushBackSlice([]interface{}{9, 10, 11})  // [11 _ _ 6 | 7 8 9 10]
+	assert.Equal(t, cb.ToArray(), []interface{}{6, 7, 8, 9, 10, 11})
+	assert.Nil(t, cb.CheckInvariants())
+	var chunks [][]interface{}
+	for chunk := range cb.Chunks() {
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, chunks, [][]interface{}{{6}, {7, 8, 9, 10}, {11}})
+	first, second := cb.Slices()
+	assert.Equal(t, first, []interface{}{6, 7, 8, 9, 10, 11})
+	assert.Nil(t, second)
+	assert.Panics(t, func() {
+		cb.Raw()
+	})
+
+	cb.DiscardFront(2) // [11 _ _ _ | _ 8 9 10]
+	front := cb.blocks[1]
+	cb.Resize(10) // [_ 8 9 10 | 11 _ _ _ | _ _ _ _]
+	assert.Equal(t, len(cb.blocks), 3)
+	assert.Equal(t, &cb.blocks[0][0], &front[0])
+	assert.Equal(t, cb.ToArray(), []interface{}{8, 9, 10, 11})
+	assert.Nil(t, cb.CheckInvariants())
+	cb.Resize(3) // [_ 8 9 10]
+	assert.Equal(t, len(cb.blocks), 1)
+	assert.Equal(t, cb.ToArray(), []interface{}{8, 9, 10})
+	assert.Nil(t, cb.CheckInvariants())
+
+	data, e := cb.Snapshot()
+	assert.Nil(t, e)
+	var restored CircularBuffer
+	assert.Nil(t, restored.Restore(data))
+	assert.Equal(t, restored.ToArray(), []interface{}{8, 9, 10})
+	assert.Nil(t, cb.RestoreWithCapacity(data, 2))
+	assert.Equal(t, cb.blockSize, 4)
+	assert.Equal(t, cb.ToArray(), []interface{}{9, 10})
+	assert.Nil(t, cb.CheckInvariants())
+}
+
+func TestWithSegmentedStorageMatchesBackingArray(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	ints := func(from, n int) []interface{} {
+		vs := make([]interface{}, n)
+		for k := range vs {
+			vs[k] = from + k
+		}
+		return vs
+	}
+	for _, blockSize := range []int{1, 3, 4, 7} {
+		var evicted, segmentedEvicted []interface{}
+		cb := NewCircularBuffer(5, WithEvictionCallback(func(v interface{}) {
+			evicted = append(evicted, v)
+		}))
+		sb := NewCircularBuffer(5, WithSegmentedStorage(blockSize), WithEvictionCallback(func(v interface{}) {
+			segmentedEvicted = append(segmentedEvicted, v)
+		}))
+		for step := 0; step < 2000; step++ {
+			op, v := r.IntN(18), r.IntN(100)
+			i, j := r.IntN(cb.Size()+1), r.IntN(cb.Size()+1)
+			i, j = min(i, j), max(i, j)
+			for _, b := range []*CircularBuffer{&cb, &sb} {
+				switch op {
+				case 0:
+					b.PushBack(v)
+				case 1:
+					b.PushFront(v)
+				case 2:
+					b.PopBack()
+				case 3:
+					b.PopFront()
+				case 4:
+					b.PushBackSlice(ints(v, i+j))
+				case 5:
+					b.PushFrontSlice(ints(v, i+j))
+				case 6:
+					b.InsertAt(i, v)
+				case 7:
+					b.RemoveAt(j - 1)
+				case 8:
+					b.Delete(i, j)
+				case 9:
+					b.Replace(i, j, ints(v, v%4)...)
+				case 10:
+					b.Resize(1 + v%12)
+				case 11:
+					b.DiscardFront(i)
+				case 12:
+					b.Truncate(j)
+				case 13:
+					b.RemoveFunc(func(x interface{}) bool { return x.(int)%3 == 0 })
+				case 14:
+					b.SortFunc(func(a, b interface{}) int { return a.(int) - b.(int) })
+				case 15:
+					b.ShrinkToFit()
+				case 16:
+					b.PopFrontInto(make([]interface{}, i))
+				case 17:
+					b.Reset()
+				}
+			}
+			assert.Nil(t, sb.CheckInvariants())
+			assert.Equal(t, sb.ToArray(), cb.ToArray())
+			assert.Equal(t, sb.Capacity(), cb.Capacity())
+			assert.Equal(t, sb.NextSeq(), cb.NextSeq())
+			assert.Equal(t, sb.Tail(i), cb.Tail(i))
+			assert.Equal(t, sb.Index(v), cb.Index(v))
+			assert.Equal(t, sb.LastIndexFunc(func(x interface{}) bool { return x == v }), cb.LastIndexFunc(func(x interface{}) bool { return x == v }))
+		}
+		assert.Equal(t, segmentedEvicted, evicted)
+	}
+}
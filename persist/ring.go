@@ -0,0 +1,193 @@
+// Package persist provides bounded on-disk storage of the last records
+// which survives restarts: Ring is a circular file of fixed-size records,
+// SegmentLog is a log of variable-size records split into segment files.
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+const (
+	version    = 1
+	headerSize = 32
+	// slotHeaderSize is the size of sequence number and CRC preceding each record.
+	slotHeaderSize = 12
+)
+
+var magic = []byte("GOCBRING")
+
+// Ring is a circular file of fixed-size records.
+// Each record is written into its slot together with a sequence number and
+// a checksum, so a torn write loses at most the record being written.
+// Ring is not safe for concurrent use.
+type Ring struct {
+	f          *os.File
+	capacity   int
+	recordSize int
+	next       uint64 // sequence number of the next record, starting from 1
+	valid      []bool // slots holding intact records
+}
+
+// Open opens the ring file at path, creating it if it does not exist.
+// The capacity and record size of an existing file must match.
+func Open(path string, capacity, recordSize int) (*Ring, error) {
+	if capacity <= 0 || recordSize <= 0 {
+		return nil, errors.New("capacity and record size must be positive")
+	}
+	f, e := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if e != nil {
+		return nil, e
+	}
+	r := &Ring{f: f, capacity: capacity, recordSize: recordSize, next: 1, valid: make([]bool, capacity)}
+	if e = r.init(); e != nil {
+		f.Close()
+		return nil, e
+	}
+	return r, nil
+}
+
+// init writes the header into an empty file or validates and recovers an existing one.
+// The file is extended to its full size before the header is written, so a crash
+// during initialization leaves a zero or partial header, which is initialized again.
+func (r *Ring) init() error {
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	binary.LittleEndian.PutUint32(header[8:], version)
+	binary.LittleEndian.PutUint32(header[12:], uint32(r.capacity))
+	binary.LittleEndian.PutUint32(header[16:], uint32(r.recordSize))
+
+	existing := make([]byte, headerSize)
+	n, e := r.f.ReadAt(existing, 0)
+	if e != nil && e != io.EOF {
+		return e
+	}
+	torn := n < headerSize && bytes.Equal(existing[:n], header[:n])
+	if torn || bytes.Equal(existing, make([]byte, headerSize)) {
+		if e = r.f.Truncate(int64(headerSize + r.capacity*r.slotSize())); e != nil {
+			return e
+		}
+		_, e = r.f.WriteAt(header, 0)
+		return e
+	}
+	if !bytes.Equal(existing, header) {
+		return errors.New("incompatible ring file")
+	}
+	return r.recover()
+}
+
+// recover finds intact records and the next sequence number.
+func (r *Ring) recover() error {
+	seqs := make([]uint64, r.capacity)
+	var last uint64
+	for slot := range seqs {
+		seq, _, e := r.readSlot(slot)
+		if e != nil {
+			return e
+		}
+		seqs[slot] = seq
+		if seq > last {
+			last = seq
+		}
+	}
+	for slot, seq := range seqs {
+		r.valid[slot] = seq != 0 && seq+uint64(r.capacity) > last
+	}
+	r.next = last + 1
+	return nil
+}
+
+func (r *Ring) slotSize() int {
+	return slotHeaderSize + r.recordSize
+}
+
+func (r *Ring) offset(slot int) int64 {
+	return int64(headerSize + slot*r.slotSize())
+}
+
+// readSlot returns the sequence number and the record of slot,
+// or zero sequence number if the slot is empty, damaged or cut off.
+func (r *Ring) readSlot(slot int) (uint64, []byte, error) {
+	b := make([]byte, r.slotSize())
+	if _, e := r.f.ReadAt(b, r.offset(slot)); e == io.EOF {
+		return 0, nil, nil
+	} else if e != nil {
+		return 0, nil, e
+	}
+	seq := binary.LittleEndian.Uint64(b)
+	sum := binary.LittleEndian.Uint32(b[8:])
+	if seq == 0 || sum != checksum(b[:8], b[slotHeaderSize:]) {
+		return 0, nil, nil
+	}
+	return seq, b[slotHeaderSize:], nil
+}
+
+func checksum(seq, record []byte) uint32 {
+	return crc32.Update(crc32.ChecksumIEEE(seq), crc32.IEEETable, record)
+}
+
+// Close closes the ring file.
+func (r *Ring) Close() error {
+	return r.f.Close()
+}
+
+// Len returns number of intact records.
+func (r *Ring) Len() int {
+	n := 0
+	for _, v := range r.valid {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// Push appends record, overwriting the oldest one if the ring is full.
+// The record must be exactly of the record size.
+func (r *Ring) Push(record []byte) error {
+	if len(record) != r.recordSize {
+		return fmt.Errorf("record of %d bytes instead of %d", len(record), r.recordSize)
+	}
+	b := make([]byte, r.slotSize())
+	binary.LittleEndian.PutUint64(b, r.next)
+	binary.LittleEndian.PutUint32(b[8:], checksum(b[:8], record))
+	copy(b[slotHeaderSize:], record)
+
+	slot := int(r.next % uint64(r.capacity))
+	r.valid[slot] = false
+	if _, e := r.f.WriteAt(b, r.offset(slot)); e != nil {
+		return e
+	}
+	r.valid[slot] = true
+	r.next++
+	return nil
+}
+
+// Records returns intact records from the oldest to the newest.
+func (r *Ring) Records() ([][]byte, error) {
+	var records [][]byte
+	for i := 0; i < r.capacity; i++ {
+		slot := int((r.next + uint64(i)) % uint64(r.capacity))
+		if !r.valid[slot] {
+			continue
+		}
+		_, record, e := r.readSlot(slot)
+		if e != nil {
+			return nil, e
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Sync commits written records to stable storage.
+func (r *Ring) Sync() error {
+	return r.f.Sync()
+}
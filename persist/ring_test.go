@@ -0,0 +1,115 @@
+package persist
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+
+	r, e := Open(path, 4, 2)
+	assert.Nil(t, e)
+	assert.Zero(t, r.Len())
+	assert.Nil(t, r.Close())
+
+	info, e := os.Stat(path)
+	assert.Nil(t, e)
+	assert.Equal(t, info.Size(), int64(headerSize+4*(slotHeaderSize+2)))
+
+	_, e = Open(path, 4, 3)
+	assert.NotNil(t, e)
+	_, e = Open(path, 0, 2)
+	assert.NotNil(t, e)
+
+	r, e = Open(path, 4, 2)
+	assert.Nil(t, e)
+	assert.Nil(t, r.Close())
+}
+
+func TestOpenTornFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	r, _ := Open(path, 4, 2)
+	r.Push([]byte{0, 1})
+	r.Close()
+
+	// Simulate crashes during initialization and a cut off slot.
+	for _, size := range []int64{0, 10, headerSize, headerSize + slotHeaderSize + 5} {
+		assert.Nil(t, os.Truncate(path, size))
+		r, e := Open(path, 4, 2)
+		assert.Nil(t, e)
+		assert.Zero(t, r.Len())
+		assert.Nil(t, r.Push([]byte{2, 3}))
+		assert.Nil(t, r.Close())
+	}
+
+	assert.Nil(t, os.WriteFile(path, make([]byte, headerSize+4*(slotHeaderSize+2)), 0644))
+	r, e := Open(path, 4, 2)
+	assert.Nil(t, e)
+	assert.Zero(t, r.Len())
+	assert.Nil(t, r.Close())
+
+	assert.Nil(t, os.WriteFile(path, []byte("something"), 0644))
+	_, e = Open(path, 4, 2)
+	assert.NotNil(t, e)
+}
+
+func TestRingLen(t *testing.T) {
+	r, _ := Open(filepath.Join(t.TempDir(), "ring"), 2, 1)
+	defer r.Close()
+
+	r.Push([]byte{0})
+	assert.Equal(t, r.Len(), 1)
+	r.Push([]byte{1})
+	r.Push([]byte{2})
+	assert.Equal(t, r.Len(), 2)
+}
+
+func TestRingPush(t *testing.T) {
+	r, _ := Open(filepath.Join(t.TempDir(), "ring"), 2, 1)
+	defer r.Close()
+
+	assert.NotNil(t, r.Push([]byte{0, 1}))
+	assert.Nil(t, r.Push([]byte{0}))
+}
+
+func TestRingRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	r, _ := Open(path, 3, 1)
+
+	records, e := r.Records()
+	assert.Nil(t, e)
+	assert.Empty(t, records)
+
+	for i := byte(0); i < 5; i++ {
+		r.Push([]byte{i})
+	}
+	records, e = r.Records()
+	assert.Nil(t, e)
+	assert.Equal(t, records, [][]byte{{2}, {3}, {4}})
+	assert.Nil(t, r.Sync())
+	assert.Nil(t, r.Close())
+
+	r, e = Open(path, 3, 1)
+	assert.Nil(t, e)
+	records, _ = r.Records()
+	assert.Equal(t, records, [][]byte{{2}, {3}, {4}})
+
+	r.Push([]byte{5})
+	records, _ = r.Records()
+	assert.Equal(t, records, [][]byte{{3}, {4}, {5}})
+	assert.Nil(t, r.Close())
+
+	// Damage the record 3 written with sequence number 4 into slot 4 % 3.
+	f, _ := os.OpenFile(path, os.O_RDWR, 0)
+	f.WriteAt([]byte{0xff}, int64(headerSize+1*(slotHeaderSize+1)+slotHeaderSize))
+	f.Close()
+
+	r, _ = Open(path, 3, 1)
+	defer r.Close()
+	records, _ = r.Records()
+	assert.Equal(t, records, [][]byte{{4}, {5}})
+	assert.Equal(t, r.Len(), 2)
+}
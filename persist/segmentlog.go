@@ -0,0 +1,230 @@
+package persist
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	segmentSuffix = ".seg"
+	// recordHeaderSize is the size of length and CRC preceding each record in a segment.
+	// The CRC covers the length too, so a zero-filled tail isn't read as empty records.
+	recordHeaderSize = 8
+)
+
+// SegmentLog is a log of records split into segment files in a directory.
+// Records are appended to the newest segment; when it reaches the segment size
+// a new one is started, and the oldest segments are deleted while the total size
+// exceeds the budget. SegmentLog is not safe for concurrent use.
+type SegmentLog struct {
+	dir         string
+	segmentSize int64
+	budget      int64
+	segments    []segment // from the oldest to the newest
+	f           *os.File  // the newest segment
+}
+
+type segment struct {
+	index uint64
+	size  int64
+}
+
+// OpenSegmentLog opens the log in dir, creating the directory if needed.
+// A torn record at the end of the newest segment is discarded.
+func OpenSegmentLog(dir string, segmentSize, budget int64) (*SegmentLog, error) {
+	if segmentSize <= 0 || budget < segmentSize {
+		return nil, errors.New("segment size must be positive and not exceed budget")
+	}
+	if e := os.MkdirAll(dir, 0755); e != nil {
+		return nil, e
+	}
+	l := &SegmentLog{dir: dir, segmentSize: segmentSize, budget: budget}
+
+	entries, e := os.ReadDir(dir)
+	if e != nil {
+		return nil, e
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		index, e := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if e != nil {
+			continue
+		}
+		info, e := entry.Info()
+		if e != nil {
+			return nil, e
+		}
+		l.segments = append(l.segments, segment{index: index, size: info.Size()})
+	}
+	sort.Slice(l.segments, func(i, j int) bool {
+		return l.segments[i].index < l.segments[j].index
+	})
+
+	if len(l.segments) == 0 {
+		return l, l.rotate()
+	}
+	last := &l.segments[len(l.segments)-1]
+	l.f, e = os.OpenFile(l.path(last.index), os.O_RDWR, 0644)
+	if e != nil {
+		return nil, e
+	}
+	valid, e := scanSegment(l.f, l.maxRecordSize(), nil)
+	if e == nil && valid < last.size {
+		e = l.f.Truncate(valid)
+	}
+	if e == nil {
+		_, e = l.f.Seek(valid, io.SeekStart)
+	}
+	if e != nil {
+		l.f.Close()
+		return nil, e
+	}
+	last.size = valid
+	return l, nil
+}
+
+func (l *SegmentLog) path(index uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", index, segmentSuffix))
+}
+
+// Append writes record into the newest segment, starting a new segment
+// and deleting the oldest ones if needed. The record together with its header
+// must fit into a segment.
+func (l *SegmentLog) Append(record []byte) error {
+	if int64(len(record)) > l.maxRecordSize() {
+		return fmt.Errorf("record of %d bytes exceeds segment size", len(record))
+	}
+	last := &l.segments[len(l.segments)-1]
+	size := int64(recordHeaderSize + len(record))
+	if last.size > 0 && last.size+size > l.segmentSize {
+		if e := l.rotate(); e != nil {
+			return e
+		}
+		last = &l.segments[len(l.segments)-1]
+	}
+
+	b := make([]byte, size)
+	binary.LittleEndian.PutUint32(b, uint32(len(record)))
+	binary.LittleEndian.PutUint32(b[4:], recordChecksum(b, record))
+	copy(b[recordHeaderSize:], record)
+	if _, e := l.f.Write(b); e != nil {
+		// Cut off the torn record, so that later records don't follow it.
+		if te := l.f.Truncate(last.size); te != nil {
+			return errors.Join(e, te)
+		}
+		_, se := l.f.Seek(last.size, io.SeekStart)
+		return errors.Join(e, se)
+	}
+	last.size += size
+	return nil
+}
+
+// Close closes the newest segment.
+func (l *SegmentLog) Close() error {
+	return l.f.Close()
+}
+
+// Do calls function f on each surviving record from the oldest to the newest.
+func (l *SegmentLog) Do(f func([]byte) error) error {
+	for _, s := range l.segments {
+		sf, e := os.Open(l.path(s.index))
+		if e != nil {
+			return e
+		}
+		_, e = scanSegment(io.LimitReader(sf, s.size), l.maxRecordSize(), f)
+		sf.Close()
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// maxRecordSize returns the size of the largest record fitting into a segment.
+func (l *SegmentLog) maxRecordSize() int64 {
+	return l.segmentSize - recordHeaderSize
+}
+
+// Sync commits the newest segment to stable storage.
+func (l *SegmentLog) Sync() error {
+	return l.f.Sync()
+}
+
+// rotate starts a new segment and deletes the oldest ones exceeding the budget.
+func (l *SegmentLog) rotate() error {
+	var index uint64
+	if len(l.segments) > 0 {
+		index = l.segments[len(l.segments)-1].index + 1
+	}
+	f, e := os.OpenFile(l.path(index), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if e != nil {
+		return e
+	}
+	if l.f != nil {
+		l.f.Close()
+	}
+	l.f = f
+	l.segments = append(l.segments, segment{index: index})
+
+	// The new segment may grow up to segmentSize, so it is accounted as full.
+	total := l.segmentSize
+	for _, s := range l.segments[:len(l.segments)-1] {
+		total += s.size
+	}
+	for total > l.budget && len(l.segments) > 1 {
+		if e := os.Remove(l.path(l.segments[0].index)); e != nil {
+			return e
+		}
+		total -= l.segments[0].size
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// recordChecksum returns the CRC of the length in header followed by record.
+func recordChecksum(header, record []byte) uint32 {
+	return crc32.Update(crc32.ChecksumIEEE(header[:4]), crc32.IEEETable, record)
+}
+
+// scanSegment calls f on each intact record read from r, if f is not nil,
+// and returns the size of the intact prefix of the segment.
+// A length above maxRecord marks corruption and is rejected before allocation.
+func scanSegment(r io.Reader, maxRecord int64, f func([]byte) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var valid int64
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, e := io.ReadFull(br, header); e != nil {
+			return valid, nil
+		}
+		length := binary.LittleEndian.Uint32(header)
+		if int64(length) > maxRecord {
+			return valid, nil
+		}
+		record := make([]byte, length)
+		if _, e := io.ReadFull(br, record); e != nil {
+			return valid, nil
+		}
+		if recordChecksum(header, record) != binary.LittleEndian.Uint32(header[4:]) {
+			return valid, nil
+		}
+		if f != nil {
+			if e := f(record); e != nil {
+				return valid, e
+			}
+		}
+		valid += int64(recordHeaderSize + len(record))
+	}
+}
@@ -0,0 +1,140 @@
+package persist
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func records(l *SegmentLog) []string {
+	var rs []string
+	l.Do(func(record []byte) error {
+		rs = append(rs, string(record))
+		return nil
+	})
+	return rs
+}
+
+func TestOpenSegmentLog(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "log")
+
+	_, e := OpenSegmentLog(dir, 0, 10)
+	assert.NotNil(t, e)
+	_, e = OpenSegmentLog(dir, 10, 5)
+	assert.NotNil(t, e)
+
+	l, e := OpenSegmentLog(dir, 20, 40)
+	assert.Nil(t, e)
+	l.Append([]byte("ab"))
+	l.Append([]byte("cd"))
+	l.Close()
+
+	// Simulate a torn write at the end of the newest segment.
+	f, _ := os.OpenFile(filepath.Join(dir, "00000000000000000000.seg"), os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{5, 0, 0, 0, 1})
+	f.Close()
+
+	l, e = OpenSegmentLog(dir, 20, 40)
+	assert.Nil(t, e)
+	defer l.Close()
+	assert.Equal(t, records(l), []string{"ab", "cd"})
+
+	l.Append([]byte("ef"))
+	assert.Equal(t, records(l), []string{"ab", "cd", "ef"})
+}
+
+func TestOpenSegmentLogCorruptTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00000000000000000000.seg")
+
+	l, _ := OpenSegmentLog(dir, 100, 200)
+	l.Append([]byte("ab"))
+	l.Close()
+
+	// Simulate a zero-filled tail left by a crash.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write(make([]byte, 64))
+	f.Close()
+
+	l, e := OpenSegmentLog(dir, 100, 200)
+	assert.Nil(t, e)
+	assert.Equal(t, records(l), []string{"ab"})
+	l.Close()
+	info, _ := os.Stat(path)
+	assert.Equal(t, info.Size(), int64(10))
+
+	// Simulate a corrupt length.
+	f, _ = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	f.Close()
+
+	l, e = OpenSegmentLog(dir, 100, 200)
+	assert.Nil(t, e)
+	defer l.Close()
+	assert.Equal(t, records(l), []string{"ab"})
+	info, _ = os.Stat(path)
+	assert.Equal(t, info.Size(), int64(10))
+}
+
+func TestSegmentLogAppend(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := OpenSegmentLog(dir, 20, 40)
+	defer l.Close()
+
+	for _, r := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		assert.Nil(t, l.Append([]byte(r)))
+	}
+	// Each record takes 9 bytes, so a segment holds two of them
+	// and at most two segments fit into the budget.
+	assert.Equal(t, records(l), []string{"e", "f", "g"})
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 2)
+
+	assert.NotNil(t, l.Append(make([]byte, 13)))
+	assert.Nil(t, l.Append(make([]byte, 12)))
+	assert.Nil(t, l.Append(nil))
+	assert.Equal(t, records(l), []string{string(make([]byte, 12)), ""})
+}
+
+func TestSegmentLogAppendError(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := OpenSegmentLog(dir, 100, 200)
+	defer l.Close()
+	l.Append([]byte("ab"))
+
+	// Simulate a failed write.
+	f := l.f
+	l.f, _ = os.Open(filepath.Join(dir, "00000000000000000000.seg"))
+	assert.NotNil(t, l.Append([]byte("cd")))
+	assert.Equal(t, l.segments[0].size, int64(10))
+	l.f.Close()
+	l.f = f
+
+	assert.Nil(t, l.Append([]byte("ef")))
+	assert.Equal(t, records(l), []string{"ab", "ef"})
+}
+
+func TestSegmentLogDo(t *testing.T) {
+	l, _ := OpenSegmentLog(t.TempDir(), 20, 40)
+	defer l.Close()
+	assert.Empty(t, records(l))
+
+	l.Append([]byte("a"))
+	l.Append([]byte("b"))
+	n := 0
+	e := l.Do(func(record []byte) error {
+		n++
+		return errors.New("test error")
+	})
+	assert.NotNil(t, e)
+	assert.Equal(t, n, 1)
+}
+
+func TestSegmentLogSync(t *testing.T) {
+	l, _ := OpenSegmentLog(t.TempDir(), 20, 40)
+	defer l.Close()
+	assert.Nil(t, l.Sync())
+}
@@ -0,0 +1,49 @@
+package gocontainers
+
+import "sync"
+
+// Pool recycles CircularBuffers of the same capacity and options,
+// so short-lived buffers don't allocate backing arrays every time.
+// It is safe for concurrent use.
+type Pool struct {
+	capacity int
+	opts     []Option
+	pool     sync.Pool
+}
+
+// NewPool is the constructor function for Pool.
+// Buffers are created by NewCircularBuffer with capacity and opts.
+func NewPool(capacity int, opts ...Option) *Pool {
+	p := &Pool{capacity: capacity, opts: opts}
+	p.capacity = p.fresh().capacity
+	return p
+}
+
+// fresh returns a new CircularBuffer without backing array.
+func (p *Pool) fresh() CircularBuffer {
+	opts := append(p.opts[:len(p.opts):len(p.opts)], WithLazyAllocation())
+	return NewCircularBuffer(p.capacity, opts...)
+}
+
+// Get returns an empty CircularBuffer from Pool or a new one.
+func (p *Pool) Get() *CircularBuffer {
+	if cb, ok := p.pool.Get().(*CircularBuffer); ok {
+		return cb
+	}
+	cb := NewCircularBuffer(p.capacity, p.opts...)
+	return &cb
+}
+
+// Release clears cb, resets its statistics and puts it into Pool.
+// cb must not be used afterwards. Buffers whose capacity was changed
+// are left to the garbage collector instead.
+func (p *Pool) Release(cb *CircularBuffer) {
+	if cb.capacity != p.capacity || cb.slots() != cb.ring {
+		return
+	}
+	cb.clearSlots(0, cb.size)
+	buffer, blocks := cb.buffer, cb.blocks
+	*cb = p.fresh()
+	cb.buffer, cb.blocks = buffer, blocks
+	p.pool.Put(cb)
+}
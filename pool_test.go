@@ -0,0 +1,63 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewPool(t *testing.T) {
+	p := NewPool(3, WithPowerOfTwoCapacity())
+	assert.Equal(t, p.capacity, 4)
+	assert.Equal(t, p.Get().Capacity(), 4)
+}
+
+func TestPoolGet(t *testing.T) {
+	p := NewPool(2)
+
+	cb := p.Get()
+	assert.Equal(t, cb.Capacity(), 2)
+	assert.True(t, cb.Empty())
+
+	cb.PushBack(0) // [0 _]
+	assert.Equal(t, cb.ToArray(), []interface{}{0})
+}
+
+func TestPoolRelease(t *testing.T) {
+	var evicted []interface{}
+	p := NewPool(2, WithEvictionCallback(func(v interface{}) {
+		evicted = append(evicted, v)
+	}))
+
+	cb := p.Get()
+	cb.PushBack(0) // [0 _]
+	cb.PushBack(1) // [0 1]
+	cb.PushBack(2) // [2 1]
+	buffer := cb.buffer
+
+	p.Release(cb)
+	assert.True(t, cb.Empty())
+	assert.Equal(t, cb.buffer, []interface{}{nil, nil})
+	assert.Equal(t, &cb.buffer[0], &buffer[0])
+	assert.Equal(t, cb.Stats(), Stats{})
+	assert.Equal(t, cb.NextSeq(), uint64(0))
+	assert.Nil(t, cb.CheckInvariants())
+
+	cb.PushBack(3) // [3 _]
+	cb.PushBack(4) // [3 4]
+	cb.PushBack(5) // [5 4]
+	assert.Equal(t, evicted, []interface{}{0, 3})
+
+	cb.Resize(3)
+	p.Release(cb)
+	assert.Equal(t, cb.ToArray(), []interface{}{4, 5})
+
+	p = NewPool(3, WithSegmentedStorage(2))
+	cb = p.Get()
+	cb.PushBackSlice([]interface{}{0, 1, 2, 3}) // [1 2 | 3 _]
+	blocks := cb.blocks
+
+	p.Release(cb)
+	assert.Equal(t, cb.blocks, [][]interface{}{{nil, nil}, {nil, nil}})
+	assert.Equal(t, &cb.blocks[0][0], &blocks[0][0])
+	assert.Nil(t, cb.CheckInvariants())
+}
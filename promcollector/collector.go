@@ -0,0 +1,89 @@
+// Package promcollector exposes statistics of named CircularBuffers
+// as Prometheus metrics.
+package promcollector
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rvncerr/gocontainers"
+)
+
+// Collector is a prometheus.Collector reporting statistics of named rings.
+// It is safe for concurrent use.
+type Collector struct {
+	mu    sync.Mutex
+	rings map[string]ring
+
+	length     *prometheus.Desc
+	capacity   *prometheus.Desc
+	pushes     *prometheus.Desc
+	pops       *prometheus.Desc
+	overwrites *prometheus.Desc
+}
+
+type ring struct {
+	cb *gocontainers.CircularBuffer
+	mu sync.Locker
+}
+
+// New is the constructor function for Collector.
+// Metrics are named <namespace>_circularbuffer_<metric> and labelled by ring.
+func New(namespace string) *Collector {
+	labels := []string{"ring"}
+	fqName := func(name string) string {
+		return prometheus.BuildFQName(namespace, "circularbuffer", name)
+	}
+	return &Collector{
+		rings:      make(map[string]ring),
+		length:     prometheus.NewDesc(fqName("length"), "Number of elements in the ring.", labels, nil),
+		capacity:   prometheus.NewDesc(fqName("capacity"), "Maximum number of elements in the ring.", labels, nil),
+		pushes:     prometheus.NewDesc(fqName("pushes_total"), "Number of elements pushed into the ring.", labels, nil),
+		pops:       prometheus.NewDesc(fqName("pops_total"), "Number of elements popped from the ring.", labels, nil),
+		overwrites: prometheus.NewDesc(fqName("overwrites_total"), "Number of elements overwritten in the full ring.", labels, nil),
+	}
+}
+
+// Add registers cb under name, replacing any ring registered before.
+// If mu is not nil, it is held while cb is read.
+func (c *Collector) Add(name string, cb *gocontainers.CircularBuffer, mu sync.Locker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rings[name] = ring{cb: cb, mu: mu}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, r := range c.rings {
+		if r.mu != nil {
+			r.mu.Lock()
+		}
+		stats, capacity := r.cb.Stats(), r.cb.Capacity()
+		if r.mu != nil {
+			r.mu.Unlock()
+		}
+		ch <- prometheus.MustNewConstMetric(c.length, prometheus.GaugeValue, float64(stats.Size), name)
+		ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(capacity), name)
+		ch <- prometheus.MustNewConstMetric(c.pushes, prometheus.CounterValue, float64(stats.PushBacks+stats.PushFronts), name)
+		ch <- prometheus.MustNewConstMetric(c.pops, prometheus.CounterValue, float64(stats.PopBacks+stats.PopFronts), name)
+		ch <- prometheus.MustNewConstMetric(c.overwrites, prometheus.CounterValue, float64(stats.Overwrites), name)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.length
+	ch <- c.capacity
+	ch <- c.pushes
+	ch <- c.pops
+	ch <- c.overwrites
+}
+
+// Remove unregisters the ring registered under name.
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.rings, name)
+}
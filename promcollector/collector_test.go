@@ -0,0 +1,50 @@
+package promcollector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rvncerr/gocontainers"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func collect(c prometheus.Collector) []prometheus.Metric {
+	ch := make(chan prometheus.Metric, 64)
+	c.Collect(ch)
+	close(ch)
+
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestCollectorAdd(t *testing.T) {
+	c := New("test")
+	assert.Empty(t, collect(c))
+
+	a := gocontainers.NewCircularBuffer(4)
+	b := gocontainers.NewCircularBuffer(4)
+	var mu sync.Mutex
+	c.Add("a", &a, nil)
+	c.Add("b", &b, &mu)
+	assert.Len(t, collect(c), 10)
+
+	c.Add("b", &a, nil)
+	assert.Len(t, collect(c), 10)
+}
+
+func TestCollectorDescribe(t *testing.T) {
+	ch := make(chan *prometheus.Desc, 64)
+	New("test").Describe(ch)
+	assert.Len(t, ch, 5)
+}
+
+func TestCollectorRemove(t *testing.T) {
+	c := New("test")
+	cb := gocontainers.NewCircularBuffer(4)
+	c.Add("a", &cb, nil)
+	c.Remove("a")
+	assert.Empty(t, collect(c))
+}
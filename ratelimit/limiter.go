@@ -0,0 +1,68 @@
+// Package ratelimit provides a sliding-window-log rate limiter
+// backed by CircularBuffer.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rvncerr/gocontainers"
+)
+
+// Limiter allows at most limit events within any window.
+// It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	log    gocontainers.CircularBuffer // times of allowed events
+	window time.Duration
+	now    func() time.Time
+}
+
+// New is the constructor function for Limiter.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		log:    gocontainers.NewCircularBuffer(limit),
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Allow reports whether an event may happen now and records it if so.
+func (l *Limiter) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now and records them if so.
+// Either all n events are allowed or none of them.
+func (l *Limiter) AllowN(n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.expire(now)
+	if n > l.log.Capacity()-l.log.Size() {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		l.log.PushBack(now)
+	}
+	return true
+}
+
+// Remaining returns the number of events which may happen now.
+func (l *Limiter) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire(l.now())
+	return l.log.Capacity() - l.log.Size()
+}
+
+// expire removes events which left the window.
+func (l *Limiter) expire(now time.Time) {
+	for {
+		v, e := l.log.Front()
+		if e != nil || now.Sub(v.(time.Time)) < l.window {
+			return
+		}
+		l.log.PopFront()
+	}
+}
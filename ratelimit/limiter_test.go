@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(limit int, window time.Duration) (*Limiter, *time.Time) {
+	l := New(limit, window)
+	clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time {
+		return clock
+	}
+	return l, &clock
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l, clock := newTestLimiter(2, time.Second)
+
+	assert.True(t, l.Allow())
+	*clock = clock.Add(500 * time.Millisecond)
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+
+	*clock = clock.Add(500 * time.Millisecond)
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+}
+
+func TestLimiterAllowN(t *testing.T) {
+	l, clock := newTestLimiter(3, time.Second)
+
+	assert.True(t, l.AllowN(2))
+	assert.False(t, l.AllowN(2))
+	assert.True(t, l.AllowN(1))
+
+	*clock = clock.Add(time.Second)
+	assert.True(t, l.AllowN(3))
+	assert.False(t, l.AllowN(4))
+}
+
+func TestLimiterRemaining(t *testing.T) {
+	l, clock := newTestLimiter(3, time.Second)
+	assert.Equal(t, l.Remaining(), 3)
+
+	l.AllowN(2)
+	assert.Equal(t, l.Remaining(), 1)
+
+	*clock = clock.Add(time.Second)
+	assert.Equal(t, l.Remaining(), 3)
+}
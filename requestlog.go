@@ -0,0 +1,94 @@
+package gocontainers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestRecord describes a request served through RequestLog.Middleware.
+type RequestRecord struct {
+	Time    time.Time
+	Method  string
+	Path    string
+	Status  int
+	Latency time.Duration
+}
+
+// RequestLog keeps the last N requests served through its middleware.
+// It is safe for concurrent use.
+type RequestLog struct {
+	cb  CircularBuffer
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewRequestLog is the constructor function for RequestLog.
+func NewRequestLog(n int) *RequestLog {
+	return &RequestLog{cb: NewCircularBuffer(n), now: time.Now}
+}
+
+// Middleware wraps next and records each request it serves.
+func (rl *RequestLog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := rl.now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		record := RequestRecord{
+			Time:    start,
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Status:  sw.status,
+			Latency: rl.now().Sub(start),
+		}
+		rl.mu.Lock()
+		rl.cb.PushBack(record)
+		rl.mu.Unlock()
+	})
+}
+
+// Records returns recorded requests from the oldest to the newest.
+func (rl *RequestLog) Records() []RequestRecord {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	records := make([]RequestRecord, 0, rl.cb.Size())
+	rl.cb.Do(func(v interface{}) error {
+		records = append(records, v.(RequestRecord))
+		return nil
+	})
+	return records
+}
+
+// statusWriter remembers the status code written by a handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if !sw.wroteHeader {
+		sw.status = status
+		sw.wroteHeader = true
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	sw.wroteHeader = true
+	return sw.ResponseWriter.Write(p)
+}
+
+// Flush passes through to the wrapped ResponseWriter if it supports flushing,
+// so streaming handlers work behind the middleware.
+func (sw *statusWriter) Flush() {
+	if flusher, ok := sw.ResponseWriter.(http.Flusher); ok {
+		sw.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
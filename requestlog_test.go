@@ -0,0 +1,58 @@
+package gocontainers
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestLogMiddleware(t *testing.T) {
+	rl := NewRequestLog(2)
+	rl.now = tickingClock()
+
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/b", nil))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	assert.Equal(t, rl.Records(), []RequestRecord{
+		{Time: time.Date(2020, 1, 1, 0, 0, 3, 0, time.UTC), Method: "POST", Path: "/b", Status: 200, Latency: time.Second},
+		{Time: time.Date(2020, 1, 1, 0, 0, 5, 0, time.UTC), Method: "GET", Path: "/missing", Status: 404, Latency: time.Second},
+	})
+}
+
+func TestRequestLogMiddlewareFlush(t *testing.T) {
+	rl := NewRequestLog(2)
+	es := NewEventStream(2, nil)
+	es.Push(0)
+
+	server := httptest.NewServer(rl.Middleware(es))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	response, e := http.DefaultClient.Do(request)
+	assert.Nil(t, e)
+	defer response.Body.Close()
+	assert.Equal(t, response.StatusCode, http.StatusOK)
+	assert.Equal(t, readEvent(t, bufio.NewReader(response.Body)), "id: 0\ndata: 0\n")
+}
+
+func TestRequestLogRecords(t *testing.T) {
+	rl := NewRequestLog(2)
+	assert.Empty(t, rl.Records())
+}
@@ -0,0 +1,175 @@
+//go:build unix
+
+// Package shmring provides a byte ring buffer in a memory-mapped file,
+// so a producer process and a consumer process can exchange data through it.
+package shmring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// Header layout. head and tail count bytes read and written since creation,
+// so head <= tail <= head+capacity and positions are taken modulo capacity.
+// They are 8-byte aligned for 64-bit atomic access on every platform,
+// including 32-bit ones, and live in separate cache lines to avoid false sharing.
+const (
+	magicOffset    = 0
+	capacityOffset = 8
+	headOffset     = 64
+	tailOffset     = 128
+	headerSize     = 192
+)
+
+var magic = []byte("GOCBSHM2")
+
+// Ring is a byte ring buffer in a memory-mapped file.
+// It is lock-free for a single producer calling Write and a single consumer
+// calling Read, even in different processes: head and tail are accessed atomically,
+// the producer publishes tail only after copying data and the consumer publishes
+// head only after copying it out. Several producers or consumers must serialize
+// their access, e.g. with a file lock.
+type Ring struct {
+	f        *os.File
+	data     []byte
+	capacity uint64
+}
+
+// Create creates or truncates the file at path and initializes a ring of capacity bytes in it.
+func Create(path string, capacity int) (*Ring, error) {
+	if capacity <= 0 {
+		return nil, errors.New("capacity must be positive")
+	}
+	f, e := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if e != nil {
+		return nil, e
+	}
+	if e = f.Truncate(int64(headerSize + capacity)); e != nil {
+		f.Close()
+		return nil, e
+	}
+	r, e := mmap(f, headerSize+capacity)
+	if e != nil {
+		return nil, e
+	}
+	copy(r.data[magicOffset:], magic)
+	binary.LittleEndian.PutUint64(r.data[capacityOffset:], uint64(capacity))
+	r.capacity = uint64(capacity)
+	return r, nil
+}
+
+// Open maps the ring created by Create at path.
+func Open(path string) (*Ring, error) {
+	f, e := os.OpenFile(path, os.O_RDWR, 0)
+	if e != nil {
+		return nil, e
+	}
+	info, e := f.Stat()
+	if e != nil {
+		f.Close()
+		return nil, e
+	}
+	if info.Size() < headerSize {
+		f.Close()
+		return nil, errors.New("not a ring file")
+	}
+	r, e := mmap(f, int(info.Size()))
+	if e != nil {
+		return nil, e
+	}
+	r.capacity = binary.LittleEndian.Uint64(r.data[capacityOffset:])
+	if !bytes.Equal(r.data[magicOffset:magicOffset+len(magic)], magic) || r.capacity != uint64(info.Size()-headerSize) {
+		r.Close()
+		return nil, errors.New("not a ring file")
+	}
+	return r, nil
+}
+
+func mmap(f *os.File, size int) (*Ring, error) {
+	data, e := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if e != nil {
+		f.Close()
+		return nil, e
+	}
+	return &Ring{f: f, data: data}, nil
+}
+
+// Capacity returns the maximum possible number of bytes in Ring.
+func (r *Ring) Capacity() int {
+	return int(r.capacity)
+}
+
+// Close unmaps Ring and closes its file.
+func (r *Ring) Close() error {
+	e := syscall.Munmap(r.data)
+	if ce := r.f.Close(); e == nil {
+		e = ce
+	}
+	return e
+}
+
+// Free returns the number of bytes which can be written.
+func (r *Ring) Free() int {
+	return int(r.capacity) - r.Len()
+}
+
+// Len returns the number of bytes which can be read.
+func (r *Ring) Len() int {
+	return int(r.tail() - r.head())
+}
+
+// Read moves up to len(p) bytes from Ring into p and returns their number.
+// Only the consumer may call Read.
+func (r *Ring) Read(p []byte) int {
+	head := r.head()
+	if avail := r.tail() - head; uint64(len(p)) > avail {
+		p = p[:avail]
+	}
+	buf := r.data[headerSize:]
+	pos := head % r.capacity
+	n := copy(p, buf[pos:])
+	copy(p[n:], buf)
+	r.setHead(head + uint64(len(p)))
+	return len(p)
+}
+
+// Write copies as much of p into Ring as fits and returns the number of bytes written.
+// Only the producer may call Write.
+func (r *Ring) Write(p []byte) int {
+	tail := r.tail()
+	free := r.capacity - (tail - r.head())
+	if uint64(len(p)) > free {
+		p = p[:free]
+	}
+	buf := r.data[headerSize:]
+	pos := tail % r.capacity
+	n := copy(buf[pos:], p)
+	copy(buf, p[n:])
+	r.setTail(tail + uint64(len(p)))
+	return len(p)
+}
+
+func (r *Ring) counter(offset int) *uint64 {
+	return (*uint64)(unsafe.Pointer(&r.data[offset]))
+}
+
+func (r *Ring) head() uint64 {
+	return atomic.LoadUint64(r.counter(headOffset))
+}
+
+func (r *Ring) setHead(v uint64) {
+	atomic.StoreUint64(r.counter(headOffset), v)
+}
+
+func (r *Ring) tail() uint64 {
+	return atomic.LoadUint64(r.counter(tailOffset))
+}
+
+func (r *Ring) setTail(v uint64) {
+	atomic.StoreUint64(r.counter(tailOffset), v)
+}
@@ -0,0 +1,123 @@
+//go:build unix
+
+package shmring
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+
+	r, e := Create(path, 8)
+	assert.Nil(t, e)
+	assert.Equal(t, r.Capacity(), 8)
+	assert.Zero(t, r.Len())
+	assert.Nil(t, r.Close())
+
+	_, e = Create(path, 0)
+	assert.NotNil(t, e)
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+
+	_, e := Open(path)
+	assert.NotNil(t, e)
+
+	os.WriteFile(path, make([]byte, headerSize+8), 0644)
+	_, e = Open(path)
+	assert.NotNil(t, e)
+
+	producer, _ := Create(path, 8)
+	defer producer.Close()
+	consumer, e := Open(path)
+	assert.Nil(t, e)
+	defer consumer.Close()
+
+	producer.Write([]byte("abc"))
+	p := make([]byte, 8)
+	n := consumer.Read(p)
+	assert.Equal(t, string(p[:n]), "abc")
+	assert.Zero(t, producer.Len())
+}
+
+func TestRingFree(t *testing.T) {
+	r, _ := Create(filepath.Join(t.TempDir(), "ring"), 8)
+	defer r.Close()
+
+	assert.Equal(t, r.Free(), 8)
+	r.Write([]byte("abc"))
+	assert.Equal(t, r.Free(), 5)
+}
+
+func TestRingConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	producer, _ := Create(path, 7)
+	defer producer.Close()
+	consumer, _ := Open(path)
+	defer consumer.Close()
+
+	const total = 100000
+	go func() {
+		p := make([]byte, 5)
+		for sent := 0; sent < total; {
+			for i := range p {
+				p[i] = byte(sent + i)
+			}
+			if total-sent < len(p) {
+				p = p[:total-sent]
+			}
+			n := producer.Write(p)
+			if n == 0 {
+				runtime.Gosched()
+			}
+			sent += n
+		}
+	}()
+
+	p := make([]byte, 3)
+	for received := 0; received < total; {
+		n := consumer.Read(p)
+		if n == 0 {
+			runtime.Gosched()
+		}
+		for i := 0; i < n; i++ {
+			if p[i] != byte(received+i) {
+				t.Fatalf("byte %d is %d", received+i, p[i])
+			}
+		}
+		received += n
+	}
+}
+
+func TestRingRead(t *testing.T) {
+	r, _ := Create(filepath.Join(t.TempDir(), "ring"), 4)
+	defer r.Close()
+
+	p := make([]byte, 4)
+	assert.Zero(t, r.Read(p))
+
+	r.Write([]byte("abc"))
+	assert.Equal(t, r.Read(p[:2]), 2)
+	assert.Equal(t, string(p[:2]), "ab")
+
+	r.Write([]byte("def")) // wraps around
+	n := r.Read(p)
+	assert.Equal(t, string(p[:n]), "cdef")
+	assert.Zero(t, r.Len())
+}
+
+func TestRingWrite(t *testing.T) {
+	r, _ := Create(filepath.Join(t.TempDir(), "ring"), 4)
+	defer r.Close()
+
+	assert.Equal(t, r.Write([]byte("abc")), 3)
+	assert.Equal(t, r.Write([]byte("def")), 1)
+	assert.Zero(t, r.Write([]byte("g")))
+	assert.Equal(t, r.Len(), 4)
+}
@@ -0,0 +1,117 @@
+// Package sloghandler provides a slog.Handler which works as a flight
+// recorder: it retains the last N records and re-emits them on demand.
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/rvncerr/gocontainers"
+)
+
+// Handler retains the last N records in a CircularBuffer.
+// Handlers derived by WithAttrs and WithGroup share the same buffer.
+type Handler struct {
+	level slog.Leveler
+	ops   []op
+	ring  *ring
+}
+
+type ring struct {
+	cb gocontainers.CircularBuffer
+	mu sync.Mutex
+}
+
+// op is a WithAttrs (group is empty) or WithGroup (attrs is nil) call.
+type op struct {
+	attrs []slog.Attr
+	group string
+}
+
+type entry struct {
+	ops    []op
+	record slog.Record
+}
+
+// New is the constructor function for Handler.
+// Records below level are dropped; nil level means slog.LevelInfo.
+func New(n int, level slog.Leveler) *Handler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &Handler{
+		level: level,
+		ring:  &ring{cb: gocontainers.NewCircularBuffer(n)},
+	}
+}
+
+// Enabled reports whether the handler retains records of level l.
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Flush re-emits retained records into target from the oldest to the newest
+// and removes them from Handler.
+func (h *Handler) Flush(ctx context.Context, target slog.Handler) error {
+	h.ring.mu.Lock()
+	entries := h.ring.cb.ToArray()
+	h.ring.cb.Clear()
+	h.ring.mu.Unlock()
+
+	for _, v := range entries {
+		en := v.(entry)
+		t := target
+		for _, o := range en.ops {
+			if o.group != "" {
+				t = t.WithGroup(o.group)
+			} else {
+				t = t.WithAttrs(o.attrs)
+			}
+		}
+		if !t.Enabled(ctx, en.record.Level) {
+			continue
+		}
+		if e := t.Handle(ctx, en.record); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Handle stores a copy of r.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	h.ring.mu.Lock()
+	defer h.ring.mu.Unlock()
+	h.ring.cb.PushBack(entry{ops: h.ops, record: r.Clone()})
+	return nil
+}
+
+// Len returns number of retained records.
+func (h *Handler) Len() int {
+	h.ring.mu.Lock()
+	defer h.ring.mu.Unlock()
+	return h.ring.cb.Size()
+}
+
+// WithAttrs returns a Handler sharing the buffer and adding attrs to records.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(op{attrs: attrs})
+}
+
+// WithGroup returns a Handler sharing the buffer and opening group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(op{group: name})
+}
+
+func (h *Handler) with(o op) *Handler {
+	ops := make([]op, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &Handler{level: h.level, ops: append(ops, o), ring: h.ring}
+}
@@ -0,0 +1,60 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTarget(b *bytes.Buffer) slog.Handler {
+	return slog.NewTextHandler(b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+}
+
+func TestHandlerEnabled(t *testing.T) {
+	h := New(4, slog.LevelWarn)
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelError))
+
+	h = New(4, nil)
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+}
+
+func TestHandlerFlush(t *testing.T) {
+	h := New(2, slog.LevelDebug)
+	logger := slog.New(h)
+
+	logger.Info("a")
+	logger.With("k", 1).Info("b")
+	logger.WithGroup("g").Info("c", "k", 2)
+	assert.Equal(t, h.Len(), 2)
+
+	var b bytes.Buffer
+	e := h.Flush(context.Background(), newTarget(&b))
+	assert.Nil(t, e)
+	assert.Zero(t, h.Len())
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Equal(t, lines, []string{
+		"level=INFO msg=b k=1",
+		"level=INFO msg=c g.k=2",
+	})
+}
+
+func TestHandlerHandle(t *testing.T) {
+	h := New(4, slog.LevelDebug)
+	assert.Zero(t, h.Len())
+
+	slog.New(h).Debug("a")
+	assert.Equal(t, h.Len(), 1)
+}
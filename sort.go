@@ -0,0 +1,23 @@
+package gocontainers
+
+// sortInterface adapts CircularBuffer to sort.Interface over logical indexes.
+type sortInterface struct {
+	cb   *CircularBuffer
+	less func(a, b interface{}) bool
+}
+
+// Len returns number of elements in CircularBuffer.
+func (s sortInterface) Len() int {
+	return s.cb.size
+}
+
+// Less compares elements of CircularBuffer by indexes.
+func (s sortInterface) Less(i, j int) bool {
+	return s.less(*s.cb.cell(s.cb.slot(i)), *s.cb.cell(s.cb.slot(j)))
+}
+
+// Swap swaps elements of CircularBuffer by indexes.
+func (s sortInterface) Swap(i, j int) {
+	i, j = s.cb.slot(i), s.cb.slot(j)
+	*s.cb.cell(i), *s.cb.cell(j) = *s.cb.cell(j), *s.cb.cell(i)
+}
@@ -0,0 +1,73 @@
+package gocontainers
+
+import "iter"
+
+// Window is a view of a sub-range of CircularBuffer. It doesn't copy elements:
+// Set and UpdateAt on CircularBuffer are visible through Window.
+// Window is invalidated by any other modification of CircularBuffer,
+// since pushes, pops and resizes move elements relative to the front.
+// Using an invalidated Window doesn't panic, but yields arbitrary elements.
+type Window struct {
+	cb    *CircularBuffer
+	start int
+	size  int
+}
+
+// All returns an iterator over indexes and elements of Window from the front to the back.
+func (w Window) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		for i := 0; i < w.size; i++ {
+			if !yield(i, *w.cb.cell(w.cb.slot(w.start + i))) {
+				return
+			}
+		}
+	}
+}
+
+// At returns element from Window by index.
+// Negative index counts from the back.
+func (w Window) At(index int) (interface{}, error) {
+	if index < 0 {
+		index += w.size
+	}
+	if 0 <= index && index < w.size {
+		return *w.cb.cell(w.cb.slot(w.start + index)), nil
+	}
+	return nil, ErrIndexOutOfRange
+}
+
+// Backward returns an iterator over indexes and elements of Window from the back to the front.
+func (w Window) Backward() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		for i := w.size - 1; i >= 0; i-- {
+			if !yield(i, *w.cb.cell(w.cb.slot(w.start + i))) {
+				return
+			}
+		}
+	}
+}
+
+// Size returns number of elements in Window.
+func (w Window) Size() int {
+	return w.size
+}
+
+// ToArray converts Window to Array.
+func (w Window) ToArray() []interface{} {
+	array := make([]interface{}, 0, w.size)
+	for _, v := range w.All() {
+		array = append(array, v)
+	}
+	return array
+}
+
+// Values returns an iterator over elements of Window from the front to the back.
+func (w Window) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, v := range w.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gocontainers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWindowAll(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+	cb.PushFront(3) // [0 1 3 2]
+
+	w, _ := cb.Window(1, 4)
+	var indexes, values []interface{}
+	for i, v := range w.All() {
+		indexes = append(indexes, i)
+		values = append(values, v)
+	}
+	assert.Equal(t, indexes, []interface{}{0, 1, 2})
+	assert.Equal(t, values, []interface{}{2, 0, 1})
+}
+
+func TestWindowAt(t *testing.T) {
+	cb := NewCircularBuffer(4)
+
+	cb.PushBack(0)  // [0 _ _ _]
+	cb.PushBack(1)  // [0 1 _ _]
+	cb.PushFront(2) // [0 1 _ 2]
+
+	w, _ := cb.Window(0, 2)
+	value, e := w.At(1)
+	assert.Equal(t, value, 0)
+	assert.Nil(t, e)
+	value, _ = w.At(-2)
+	assert.Equal(t, value, 2)
+	_, e = w.At(2)
+	assert.ErrorIs(t, e, ErrIndexOutOfRange)
+
+	cb.Set(0, 3) // [0 1 _ 3]
+	value, _ = w.At(0)
+	assert.Equal(t, value, 3)
+}
+
+func TestWindowBackward(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2, 3)
+
+	w, _ := cb.Window(1, 3)
+	var indexes, values []interface{}
+	for i, v := range w.Backward() {
+		indexes = append(indexes, i)
+		values = append(values, v)
+	}
+	assert.Equal(t, indexes, []interface{}{1, 0})
+	assert.Equal(t, values, []interface{}{2, 1})
+}
+
+func TestWindowSize(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2)
+
+	w, _ := cb.Window(1, 3)
+	assert.Equal(t, w.Size(), 2)
+	w, _ = cb.Window(3, 3)
+	assert.Equal(t, w.Size(), 0)
+	assert.Equal(t, Window{}.Size(), 0)
+}
+
+func TestWindowToArray(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2)
+
+	w, _ := cb.Window(1, 3)
+	assert.Equal(t, w.ToArray(), []interface{}{1, 2})
+	w, _ = cb.Window(1, 1)
+	assert.Equal(t, w.ToArray(), []interface{}{})
+}
+
+func TestWindowValues(t *testing.T) {
+	cb := NewCircularBufferWithValues(4, 0, 1, 2, 3)
+
+	w, _ := cb.Window(1, 4)
+	var values []interface{}
+	for v := range w.Values() {
+		values = append(values, v)
+		if v == 2 {
+			break
+		}
+	}
+	assert.Equal(t, values, []interface{}{1, 2})
+}
@@ -0,0 +1,39 @@
+package gocontainers
+
+import (
+	"io"
+	"sync"
+)
+
+// WriterCapture is an io.Writer which keeps payloads of the last N writes.
+// It is safe for concurrent use.
+type WriterCapture struct {
+	cb CircularBuffer
+	mu sync.Mutex
+}
+
+// NewWriterCapture is the constructor function for WriterCapture.
+func NewWriterCapture(n int) *WriterCapture {
+	return &WriterCapture{cb: NewCircularBuffer(n)}
+}
+
+// Dump writes captured payloads into w from the oldest to the newest.
+func (wc *WriterCapture) Dump(w io.Writer) error {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	return wc.cb.Do(func(v interface{}) error {
+		_, e := w.Write(v.([]byte))
+		return e
+	})
+}
+
+// Write stores a copy of p in WriterCapture. It never fails.
+func (wc *WriterCapture) Write(p []byte) (int, error) {
+	payload := make([]byte, len(p))
+	copy(payload, p)
+
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.cb.PushBack(payload)
+	return len(p), nil
+}
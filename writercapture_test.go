@@ -0,0 +1,44 @@
+package gocontainers
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriterCaptureDump(t *testing.T) {
+	wc := NewWriterCapture(2)
+
+	var b bytes.Buffer
+	e := wc.Dump(&b)
+	assert.Nil(t, e)
+	assert.Zero(t, b.Len())
+
+	wc.Write([]byte("a")) // [a _]
+	wc.Write([]byte("b")) // [a b]
+	wc.Write([]byte("c")) // [b c]
+
+	e = wc.Dump(&b)
+	assert.Nil(t, e)
+	assert.Equal(t, b.String(), "bc")
+}
+
+func TestWriterCaptureWrite(t *testing.T) {
+	wc := NewWriterCapture(2)
+
+	p := []byte("abc")
+	n, e := wc.Write(p)
+	assert.Equal(t, n, 3)
+	assert.Nil(t, e)
+
+	p[0] = 'x'
+
+	var b bytes.Buffer
+	wc.Dump(&b)
+	assert.Equal(t, b.String(), "abc")
+
+	wc = NewWriterCapture(0)
+	n, e = wc.Write(p)
+	assert.Equal(t, n, 3)
+	assert.Nil(t, e)
+}